@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joelvoss/release-lit/internal/release"
+
+	"github.com/urfave/cli/v2"
+)
+
+// changelogCommand maintains the changelog file without creating a release.
+func changelogCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "changelog",
+		Usage: "maintain the changelog without creating a release",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "rebuild",
+				Usage: "regenerate the whole changelog from all tags of the git history",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Usage:   "print the changes without writing the changelog file",
+				EnvVars: []string{"RELEASE_LIT_DRY_RUN"},
+			},
+		},
+		Action: func(cCtx *cli.Context) error {
+			if !cCtx.Bool("rebuild") {
+				return cli.Exit("Nothing to do. Pass --rebuild to regenerate the changelog.", 1)
+			}
+
+			opts, err := releaseOpts(cCtx)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			f, err := release.Rebuild(opts)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			// NOTE(joel): In dry-run mode we only print what would happen.
+			if cCtx.Bool("dry-run") {
+				fmt.Println("INFO: Dry run. The changelog file is not written.")
+				fmt.Printf("INFO: Changes:\n\n%s", f.Diff(opts.RootDir))
+				return nil
+			}
+
+			if err := os.WriteFile(f.Path, f.New, 0644); err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			fmt.Printf("INFO: Changelog '%s' rebuilt successfully.\n", f.Path)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/joelvoss/release-lit/internal/release"
+
+	"github.com/urfave/cli/v2"
+)
+
+// NOTE(joel): Exit code of the `latest-tag` command if no tag satisfies the
+// range. Same as the one of `next-version` if no release is due.
+const exitCodeNoTag = 3
+
+// latestTagCommand prints the latest tag that satisfies a version range.
+func latestTagCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "latest-tag",
+		Usage:     "print the latest tag that satisfies a version range, e.g. '~1.4'",
+		ArgsUsage: "<range>",
+		Description: fmt.Sprintf(
+			"Prints only the tag. If no tag satisfies the range, nothing is printed and the command exits with code %d.",
+			exitCodeNoTag,
+		),
+		Action: func(cCtx *cli.Context) error {
+			if cCtx.NArg() != 1 {
+				return cli.Exit("Pass exactly one version range, e.g. 'latest-tag ~1.4'.", 1)
+			}
+
+			opts, err := releaseOpts(cCtx)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			tag, err := release.LatestTag(opts, cCtx.Args().First())
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			if tag == nil {
+				return cli.Exit("", exitCodeNoTag)
+			}
+
+			fmt.Println(tag.Original)
+			return nil
+		},
+	}
+}
@@ -1,16 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"path"
 
-	"github.com/joelvoss/release-lit/internal/changelog"
-	"github.com/joelvoss/release-lit/internal/git"
-	"github.com/joelvoss/release-lit/internal/golang"
-	"github.com/joelvoss/release-lit/internal/node"
-	"github.com/joelvoss/release-lit/internal/python"
+	"github.com/joelvoss/release-lit/internal/publish"
+	"github.com/joelvoss/release-lit/internal/release"
 	"github.com/joelvoss/release-lit/internal/semver"
 
 	"github.com/urfave/cli/v2"
@@ -24,113 +21,238 @@ func main() {
 		Name:    "release-me",
 		Version: version,
 		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "path of the config file (default: .release-lit.{yaml,yml,json} in the git root)",
+				EnvVars: []string{"RELEASE_LIT_CONFIG"},
+			},
 			&cli.StringFlag{
 				Name:    "cpath",
 				Aliases: []string{"cp"},
 				Value:   "./CHANGELOG.md",
 				Usage:   "path of the changelog file",
+				EnvVars: []string{"RELEASE_LIT_CPATH"},
 			},
 			&cli.StringFlag{
 				Name:    "type",
 				Aliases: []string{"t"},
 				Value:   "node",
 				Usage:   "project type (node, python, go)",
+				EnvVars: []string{"RELEASE_LIT_TYPE"},
+			},
+			&cli.StringFlag{
+				Name:    "changelog-format",
+				Value:   "default",
+				Usage:   "changelog format (default, keepachangelog)",
+				EnvVars: []string{"RELEASE_LIT_CHANGELOG_FORMAT"},
+			},
+			&cli.StringFlag{
+				Name:    "template",
+				Usage:   "path of a custom changelog template (text/template)",
+				EnvVars: []string{"RELEASE_LIT_TEMPLATE"},
+			},
+			&cli.BoolFlag{
+				Name:    "contributors",
+				Usage:   "add a contributors section to the changelog",
+				EnvVars: []string{"RELEASE_LIT_CONTRIBUTORS"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "release-rule",
+				Aliases: []string{"r"},
+				Usage:   "map a commit type to a release type, e.g. 'perf=patch' (can be repeated)",
+				EnvVars: []string{"RELEASE_LIT_RELEASE_RULES"},
+			},
+			&cli.StringFlag{
+				Name:    "prerelease",
+				Aliases: []string{"p"},
+				Usage:   "create a pre-release with the given identifier, e.g. 'rc' for 2.0.0-rc.1",
+				EnvVars: []string{"RELEASE_LIT_PRERELEASE"},
+			},
+			&cli.StringFlag{
+				Name:    "version-range",
+				Usage:   "only release if the next version satisfies the range, e.g. '>=2.0.0 <3.0.0'",
+				EnvVars: []string{"RELEASE_LIT_VERSION_RANGE"},
+			},
+			&cli.StringFlag{
+				Name:    "max-version",
+				Usage:   "only release if the next version is lower than or equal to the given version, e.g. '2.x'",
+				EnvVars: []string{"RELEASE_LIT_MAX_VERSION"},
+			},
+			&cli.StringFlag{
+				Name:    "release-notes",
+				Usage:   "write only the section of the new release to the given file",
+				EnvVars: []string{"RELEASE_LIT_RELEASE_NOTES"},
+			},
+			&cli.StringFlag{
+				Name:    "release-notes-template",
+				Usage:   "path of a custom template for --release-notes (text/template)",
+				EnvVars: []string{"RELEASE_LIT_RELEASE_NOTES_TEMPLATE"},
+			},
+			&cli.StringFlag{
+				Name:    "notes-format",
+				Value:   "json",
+				Usage:   "format of the release notes written to --notes-out (json, yaml)",
+				EnvVars: []string{"RELEASE_LIT_NOTES_FORMAT"},
+			},
+			&cli.StringFlag{
+				Name:    "notes-out",
+				Usage:   "write machine-readable release notes to the given file",
+				EnvVars: []string{"RELEASE_LIT_NOTES_OUT"},
+			},
+			&cli.BoolFlag{
+				Name:    "forge-release",
+				Aliases: []string{"github-release"},
+				Usage:   "publish a release for the new tag on the forge (GitHub, GitLab, Gitea, Forgejo)",
+				EnvVars: []string{"RELEASE_LIT_FORGE_RELEASE", "RELEASE_LIT_GITHUB_RELEASE"},
+			},
+			&cli.StringFlag{
+				Name:    "forge-api-url",
+				Aliases: []string{"github-api-url"},
+				Usage:   "base URL of the forge API (default: derived from the repository URL)",
+				EnvVars: []string{"RELEASE_LIT_FORGE_API_URL"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "asset",
+				Usage:   "attach files matching the glob pattern to the published release (can be repeated)",
+				EnvVars: []string{"RELEASE_LIT_ASSETS"},
+			},
+			&cli.StringFlag{
+				Name:    "author",
+				Usage:   "name of the author of the release commit + tag (default: release-lit-bot)",
+				EnvVars: []string{"RELEASE_LIT_AUTHOR"},
+			},
+			&cli.StringFlag{
+				Name:    "email",
+				Usage:   "email of the author of the release commit + tag (default: bot@release-lit)",
+				EnvVars: []string{"RELEASE_LIT_EMAIL"},
+			},
+			&cli.BoolFlag{
+				Name:    "sign",
+				Usage:   "sign the release commit + tag and verify the signatures",
+				EnvVars: []string{"RELEASE_LIT_SIGN"},
+			},
+			&cli.StringFlag{
+				Name:    "sign-format",
+				Usage:   "signature format (openpgp, ssh). Defaults to the 'gpg.format' git config",
+				EnvVars: []string{"RELEASE_LIT_SIGN_FORMAT"},
+			},
+			&cli.StringFlag{
+				Name:    "signing-key",
+				Usage:   "GPG key id or SSH key to sign with. Defaults to the 'user.signingKey' git config",
+				EnvVars: []string{"RELEASE_LIT_SIGNING_KEY"},
+			},
+			&cli.BoolFlag{
+				Name:    "push",
+				Usage:   "push the release branch and tag to the remote (see --remote)",
+				EnvVars: []string{"RELEASE_LIT_PUSH"},
+			},
+			&cli.StringFlag{
+				Name:    "remote",
+				Value:   "origin",
+				Usage:   "remote to push the release to",
+				EnvVars: []string{"RELEASE_LIT_REMOTE"},
+			},
+			&cli.BoolFlag{
+				Name:    "allow-dirty",
+				Usage:   "allow releases from a working tree with uncommitted changes",
+				EnvVars: []string{"RELEASE_LIT_ALLOW_DIRTY"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "skip-check",
+				Usage:   "skip a pre-flight check (clean, branch, detached, upstream, tag, released). Can be repeated",
+				EnvVars: []string{"RELEASE_LIT_SKIP_CHECKS"},
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Usage:   "print the release plan without changing any files or creating a commit/tag",
+				EnvVars: []string{"RELEASE_LIT_DRY_RUN"},
 			},
 		},
+		Commands: []*cli.Command{
+			nextVersionCommand(),
+			latestTagCommand(),
+			changelogCommand(),
+		},
 		Action: func(cCtx *cli.Context) error {
 			fmt.Println("INFO: Starting release process...")
 
-			// NOTE(joel): Get git root. This conveniently also checks if the current
-			// directory is a git repository.
-			root, err := git.GetRoot(nil)
+			opts, err := releaseOpts(cCtx)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			format, err := notesFormat(cCtx)
 			if err != nil {
 				return cli.Exit(err, 1)
 			}
 
-			// NOTE(joel): Get all tags sorted by version in descending order
-			tags, err := git.GetTags(nil)
+			plan, err := release.NewPlan(opts)
 			if err != nil {
 				return cli.Exit(err, 1)
 			}
 
-			// NOTE(joel): Get sha of latest tag to use as a reference point from
-			// where new commits are analyzed.
-			// If there are no tags, we set the sha to an empty string.
-			var sha string
-			if len(tags) == 0 {
-				sha = ""
-			} else {
-				sha, err = git.GetTagHead(tags[0].Original, nil)
-				if err != nil {
-					return cli.Exit(err, 1)
-				}
+			if !plan.IsDue() {
+				fmt.Printf(
+					"INFO: No release due. The commits since '%s' don't warrant a new version.\n",
+					plan.Previous.Original,
+				)
+				return nil
 			}
 
-			// NOTE(joel): Get commits since latest tag sha. If there are no tags, we
-			// get all commits (for the changelog).
-			commits, err := git.GetCommits(sha, nil)
-			if err != nil {
-				return cli.Exit(err, 1)
+			// NOTE(joel): In dry-run mode we only print what would happen.
+			if cCtx.Bool("dry-run") {
+				printPlan(plan)
+				return nil
 			}
 
-			var newVersion *semver.Version
-			// NOTE(joel): Define new version based on release type and the last
-			// valid tagged version. If there are no tags, we start with version
-			// "1.0.0" regardless of the release type.
-			if len(tags) == 0 {
-				newVersion, err = semver.Parse("1.0.0")
-				if err != nil {
-					return cli.Exit(err, 1)
-				}
-			} else {
-				// NOTE(joel): Get next release type based on commits since last tag
-				// (or all commits if there are no tags).
-				nextRelease := git.GetNextReleaseType(commits)
-
-				// NOTE(joel): We pass the latest tag by value to the `Bump` function
-				// to avoid modifying the original tag.
-				newVersion, err = semver.Bump(*tags[0], nextRelease)
+			// NOTE(joel): The forge release points at the release tag, so the tag
+			// must be on the remote before it is published. Otherwise the forge
+			// creates the tag itself, from the default branch.
+			if cCtx.Bool("forge-release") && opts.Remote == "" {
+				return cli.Exit(errors.New(
+					"--forge-release requires --push. The release tag must be on the remote before the release is published",
+				), 1)
+			}
+
+			var publisher publish.Publisher
+			var assets []string
+			if cCtx.Bool("forge-release") {
+				publisher, assets, err = newPublisher(cCtx, opts, plan)
 				if err != nil {
 					return cli.Exit(err, 1)
 				}
 			}
 
-			// NOTE(joel): Generate changelog and write to file
-			var changelogFilePath string
-			if path.IsAbs(cCtx.String("cpath")) {
-				changelogFilePath = cCtx.String("cpath")
-			} else {
-				changelogFilePath = path.Join(root, cCtx.String("cpath"))
-			}
-			err = changelog.Generate(commits, newVersion, changelogFilePath)
-			if err != nil {
+			// NOTE(joel): Write changelog + version file, create release
+			// commit + tag and push them. The commit + tag must be on the
+			// remote before the forge release is published.
+			if err := plan.Apply(); err != nil {
 				return cli.Exit(err, 1)
 			}
 
-			// NOTE(joel): Update version file based on project type.
-			switch cCtx.String("type") {
-			case "node":
-				if err := node.UpdateVersion(newVersion, root); err != nil {
+			// NOTE(joel): Release notes are written after the release commit,
+			// so that they are never part of it.
+			if out := cCtx.String("release-notes"); out != "" {
+				if err := writeReleaseNotes(plan, out); err != nil {
 					return cli.Exit(err, 1)
 				}
-			case "python":
-				if err := python.UpdateVersion(newVersion, root); err != nil {
+			}
+			if out := cCtx.String("notes-out"); out != "" {
+				if err := writeNotes(plan, format, out); err != nil {
 					return cli.Exit(err, 1)
 				}
-			case "go":
-				if err := golang.UpdateVersion(newVersion, root); err != nil {
+			}
+
+			if publisher != nil {
+				if err := publishRelease(publisher, plan, assets); err != nil {
 					return cli.Exit(err, 1)
 				}
-			default:
-				return cli.Exit("unsupported project type", 1)
 			}
 
-			// NOTE(joel): Create release commit + tag.
-			err = git.CreateRelease(newVersion, nil)
-			if err != nil {
-				return cli.Exit(err, 1)
+			if plan.Remote != "" {
+				fmt.Printf("INFO: Release created and pushed to '%s' successfully.\n", plan.Remote)
+				return nil
 			}
-
 			fmt.Println("INFO: Release created successfully. If applicable, don't forget to push the release commit + tag.")
 			return nil
 		},
@@ -140,3 +262,20 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+// printPlan prints the computed version, changelog section and a unified diff
+// of every file the release would change.
+func printPlan(plan *release.Plan) {
+	fmt.Println("INFO: Dry run. No files are written and no commit or tag is created.")
+	fmt.Printf(
+		"INFO: Next version: %s (%s)\n",
+		plan.Version.ToString(), semver.ReleaseTypeName(plan.ReleaseType),
+	)
+	fmt.Printf("INFO: Changelog:\n\n%s\n\n", plan.Changelog)
+	fmt.Printf("INFO: Changes:\n\n%s", plan.Diff())
+	if plan.Remote != "" {
+		fmt.Printf("INFO: The release branch and tag %s would be pushed to '%s'.\n", plan.Tag(), plan.Remote)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/joelvoss/release-lit/internal/release"
+
+	"github.com/urfave/cli/v2"
+)
+
+// NOTE(joel): Exit code of the `next-version` command if no release is due.
+// It is distinct from the generic error exit code 1 so that scripts can
+// branch on it.
+const exitCodeNoRelease = 3
+
+// nextVersionCommand prints the version the next release would get.
+func nextVersionCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "next-version",
+		Usage: "print the next version without creating a release",
+		Description: fmt.Sprintf(
+			"Prints only the next version. If no release is due, nothing is printed and the command exits with code %d.",
+			exitCodeNoRelease,
+		),
+		Action: func(cCtx *cli.Context) error {
+			opts, err := releaseOpts(cCtx)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			next, err := release.NextVersion(opts)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			if !next.IsDue() {
+				return cli.Exit("", exitCodeNoRelease)
+			}
+
+			fmt.Println(next.Version.ToString())
+			return nil
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/changelog"
+	"github.com/joelvoss/release-lit/internal/release"
+
+	"github.com/urfave/cli/v2"
+)
+
+// notesFormat returns the validated format of the release notes export.
+func notesFormat(cCtx *cli.Context) (string, error) {
+	format := cCtx.String("notes-format")
+	if !slices.Contains(changelog.NotesFormats, format) {
+		return "", fmt.Errorf(
+			"unsupported notes format '%s', must be one of %s",
+			format, strings.Join(changelog.NotesFormats, ", "),
+		)
+	}
+	return format, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// writeNotes writes the machine-readable release notes of the plan to the
+// given path. Relative paths are resolved against the current directory.
+func writeNotes(plan *release.Plan, format string, path string) error {
+	out, err := plan.Notes.Marshal(format)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("Error writing release notes. Reason: '%s'", err)
+	}
+	fmt.Printf("INFO: Release notes written to '%s'.\n", path)
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// writeReleaseNotes writes the section of the new release to the given path.
+// Relative paths are resolved against the current directory.
+func writeReleaseNotes(plan *release.Plan, path string) error {
+	out := slices.Concat(plan.ReleaseNotes, []byte("\n"))
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("Error writing release notes. Reason: '%s'", err)
+	}
+	fmt.Printf("INFO: Release notes written to '%s'.\n", path)
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/publish"
+	"github.com/joelvoss/release-lit/internal/release"
+
+	"github.com/urfave/cli/v2"
+)
+
+// newPublisher returns the publisher of the forge and the release assets of
+// the plan. It is called before the release commit + tag are created, so that
+// a missing token or asset fails the release early.
+func newPublisher(cCtx *cli.Context, opts *release.Opts, plan *release.Plan) (publish.Publisher, []string, error) {
+	if plan.Forge == nil {
+		return nil, nil, errors.New(
+			"--forge-release requires a known forge. Set 'forge.type' and 'forge.url' in the config file",
+		)
+	}
+	publisher, err := publish.New(plan.Forge, opts.ForgeAPIURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	assets, err := publish.Assets(cCtx.StringSlice("asset"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return publisher, assets, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// publishRelease publishes the release of the plan with the rendered release
+// notes and the given assets.
+func publishRelease(publisher publish.Publisher, plan *release.Plan, assets []string) error {
+	tag := plan.Tag()
+	sha, err := git.GetTagHead(tag, &git.GitOpts{RootDir: plan.Root})
+	if err != nil {
+		return err
+	}
+
+	url, err := publisher.Publish(&publish.Release{
+		Tag:        tag,
+		Commit:     sha,
+		Name:       tag,
+		Notes:      string(plan.ReleaseNotes),
+		Prerelease: plan.Version.Prerelease() != "",
+		Assets:     assets,
+	})
+	if err != nil {
+		return fmt.Errorf("Error publishing release. Reason: '%s'", err)
+	}
+	fmt.Printf("INFO: Release published: %s\n", url)
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/changelog"
+	"github.com/joelvoss/release-lit/internal/check"
+	"github.com/joelvoss/release-lit/internal/config"
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/release"
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"github.com/urfave/cli/v2"
+)
+
+// releaseOpts merges CLI flags, environment variables and the config file
+// into release options. Flags and environment variables take precedence over
+// the config file, which in turn takes precedence over the flag defaults.
+func releaseOpts(cCtx *cli.Context) (*release.Opts, error) {
+	// NOTE(joel): Get git root. This conveniently also checks if the current
+	// directory is a git repository.
+	root, err := git.GetRoot(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	configPath := cCtx.String("config")
+	if configPath == "" {
+		configPath = config.Find(root)
+	}
+	c, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE(joel): Release rules are merged: defaults < config < CLI.
+	rules := git.DefaultRules
+	for t, release := range c.ReleaseRules {
+		releaseType, err := semver.ParseReleaseType(release)
+		if err != nil {
+			return nil, err
+		}
+		rules = rules.Merge(git.Rules{t: releaseType})
+	}
+	cliRules, err := git.ParseRules(cCtx.StringSlice("release-rule"))
+	if err != nil {
+		return nil, err
+	}
+	rules = rules.Merge(cliRules)
+
+	format := stringSetting(cCtx, "changelog-format", c.Changelog.Format)
+	if !slices.Contains(changelog.Formats, format) {
+		return nil, fmt.Errorf(
+			"unsupported changelog format '%s', must be one of %s",
+			format, strings.Join(changelog.Formats, ", "),
+		)
+	}
+
+	// NOTE(joel): Skipped checks are merged: config + CLI.
+	skipChecks := slices.Concat(c.Checks.Skip, cCtx.StringSlice("skip-check"))
+	for _, name := range skipChecks {
+		if !slices.Contains(check.Names, name) {
+			return nil, fmt.Errorf(
+				"unknown check '%s', must be one of %s",
+				name, strings.Join(check.Names, ", "),
+			)
+		}
+	}
+	signFormat := stringSetting(cCtx, "sign-format", c.Release.SignFormat)
+	if signFormat != "" && !slices.Contains(git.SignFormats, signFormat) {
+		return nil, fmt.Errorf(
+			"unsupported signature format '%s', must be one of %s",
+			signFormat, strings.Join(git.SignFormats, ", "),
+		)
+	}
+	email := stringSetting(cCtx, "email", c.Release.Email)
+	if email != "" && !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("invalid email '%s'", email)
+	}
+
+	remote := ""
+	if cCtx.Bool("push") {
+		remote = cCtx.String("remote")
+	}
+
+	branches := make([]release.Branch, 0, len(c.Branches))
+	for _, b := range c.Branches {
+		branches = append(branches, release.Branch{
+			Name:       b.Name,
+			Prerelease: b.Prerelease,
+			Range:      b.Range,
+		})
+	}
+
+	return &release.Opts{
+		RootDir:                  root,
+		ChangelogPath:            stringSetting(cCtx, "cpath", c.Changelog.Path),
+		ProjectType:              stringSetting(cCtx, "type", c.Type),
+		TemplatePath:             stringSetting(cCtx, "template", c.Changelog.Template),
+		ReleaseNotesTemplatePath: stringSetting(cCtx, "release-notes-template", c.Changelog.ReleaseNotesTemplate),
+		ChangelogFormat:          format,
+		Forge:                    c.Forge.Type,
+		RepoURL:                  c.Forge.URL,
+		ForgeAPIURL:              stringSetting(cCtx, "forge-api-url", c.Forge.APIURL),
+		IssueURL:                 c.Changelog.IssueURL,
+		CommitURL:                c.Changelog.CommitURL,
+		CompareURL:               c.Changelog.CompareURL,
+		Contributors:             boolSetting(cCtx, "contributors", c.Changelog.Contributors),
+		Rules:                    rules,
+		Prerelease:               cCtx.String("prerelease"),
+		Branches:                 branches,
+		VersionRange:             stringSetting(cCtx, "version-range", c.VersionRange),
+		MaxVersion:               stringSetting(cCtx, "max-version", c.MaxVersion),
+		AllowDirty:               cCtx.Bool("allow-dirty"),
+		SkipChecks:               skipChecks,
+		Git: &git.ReleaseOpts{
+			Message:    c.Release.Message,
+			Author:     stringSetting(cCtx, "author", c.Release.Author),
+			Email:      email,
+			Sign:       boolSetting(cCtx, "sign", c.Release.Sign),
+			SignFormat: signFormat,
+			SigningKey: stringSetting(cCtx, "signing-key", c.Release.SigningKey),
+		},
+		Remote: remote,
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// stringSetting returns the value of the given flag if it was set on the
+// command line or via its environment variable. Otherwise the config value is
+// returned, falling back to the flag default if the config value is empty.
+func stringSetting(cCtx *cli.Context, name string, configValue string) string {
+	if cCtx.IsSet(name) || configValue == "" {
+		return cCtx.String(name)
+	}
+	return configValue
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// boolSetting returns the value of the given flag if it was set on the command
+// line or via its environment variable, so that e.g. `--sign=false` turns off
+// the config value. Otherwise the config value is returned.
+func boolSetting(cCtx *cli.Context, name string, configValue bool) bool {
+	if cCtx.IsSet(name) {
+		return cCtx.Bool(name)
+	}
+	return configValue
+}
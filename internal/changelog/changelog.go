@@ -3,7 +3,9 @@ package changelog
 import (
 	"bytes"
 	_ "embed"
-	"os"
+	"fmt"
+	"sort"
+	"strings"
 	"text/template"
 	"time"
 
@@ -18,56 +20,252 @@ var now = time.Now
 //go:embed changelog.tpl
 var changelogTemplate string
 
+//go:embed keepachangelog.tpl
+var keepAChangelogTemplate string
+
+const (
+	// NOTE(joel): Conventional changelog with sections per commit group.
+	FormatDefault = "default"
+	// NOTE(joel): https://keepachangelog.com with an `[Unreleased]` section
+	// and link reference definitions at the bottom.
+	FormatKeepAChangelog = "keepachangelog"
+)
+
+// Formats are the supported changelog formats.
+var Formats = []string{FormatDefault, FormatKeepAChangelog}
+
+const header = "# Changelog\n"
+
+type Opts struct {
+	// NOTE(joel): Changelog format (see Formats). Defaults to FormatDefault.
+	Format string
+	// NOTE(joel): Rules that map commit types to release types. Commits are
+	// grouped into the changelog sections based on these rules.
+	Rules git.Rules
+	// NOTE(joel): Custom `text/template` source. If empty, the embedded default
+	// template is used.
+	Template string
+	// NOTE(joel): Version of the previous release (if any).
+	PreviousVersion *semver.Version
+	// NOTE(joel): Tag of the new release. Defaults to `v<version>`.
+	Tag string
+	// NOTE(joel): Release date. Defaults to today.
+	Date time.Time
+	// NOTE(joel): URL of an issue with `%s` as placeholder for the issue
+	// number, e.g. `https://github.com/owner/repo/issues/%s`. Used by the
+	// `issueLink` template function.
+	IssueURL string
+	// NOTE(joel): URL of a commit with `%s` as placeholder for the sha. Used
+	// by the `commitLink` template function.
+	CommitURL string
+	// NOTE(joel): URL comparing two tags with two `%s` placeholders for the
+	// previous and the new tag.
+	CompareURL string
+	// NOTE(joel): Render a "Contributors" section listing the unique authors
+	// of the release.
+	Contributors bool
+}
+
+// ChangelogTpl is the data passed to the changelog template. Custom templates
+// can rely on all of its fields.
 type ChangelogTpl struct {
+	// NOTE(joel): Version of the new release without `v` prefix, e.g. `1.2.0`.
 	Version string
-	Date    string
+	// NOTE(joel): Version of the previous release without `v` prefix. Empty for
+	// the first release.
+	PreviousVersion string
+	// NOTE(joel): Release date formatted as `YYYY-MM-DD`.
+	Date string
+	// NOTE(joel): URL comparing the previous and the new release. Empty if
+	// unknown.
+	CompareURL string
+	// NOTE(joel): Commits grouped by git.CommitType. Prefer `Sections`.
 	Commits map[git.CommitType][]*git.Commit
+	// NOTE(joel): Non-empty commit groups in display order.
+	Sections []Section
+	// NOTE(joel): Non-empty Keep a Changelog categories in display order.
+	Categories []Category
+	// NOTE(joel): Unique authors and co-authors of all commits of the release,
+	// sorted by name.
+	Authors []git.Committer
+	// NOTE(joel): Same as `Authors` if the contributors section is enabled,
+	// empty otherwise.
+	Contributors []git.Committer
+}
+
+// Section is a named group of commits of a release.
+type Section struct {
+	Type    git.CommitType
+	Title   string
+	Commits []*git.Commit
+}
+
+// NOTE(joel): Section titles in display order.
+var sectionTitles = []struct {
+	Type  git.CommitType
+	Title string
+}{
+	{git.CommitTypeBreaking, "BREAKING CHANGES"},
+	{git.CommitTypeFeat, "Features"},
+	{git.CommitTypeFix, "Bug Fixes"},
+	{git.CommitTypeMisc, "Miscellaneous"},
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
-// Generate creates a changelog based on the commits and new version.
-func Generate(commits []*git.Commit, newVersion *semver.Version, filepath string) error {
-	groupedCommits := git.GroupByType(commits)
+// NewChangelogTpl builds the template data for the given commits and version.
+func NewChangelogTpl(commits []*git.Commit, newVersion *semver.Version, opts *Opts) ChangelogTpl {
+	if opts == nil {
+		opts = &Opts{}
+	}
+	groupedCommits := git.GroupByType(commits, opts.Rules)
+
+	date := opts.Date
+	if date.IsZero() {
+		date = now()
+	}
+
+	data := ChangelogTpl{
+		Version:    newVersion.ToString(),
+		Date:       date.Format("2006-01-02"),
+		Commits:    groupedCommits,
+		Categories: groupByCategory(commits, opts.Rules),
+		Authors:    uniqueAuthors(commits),
+	}
+	if opts.PreviousVersion != nil {
+		data.PreviousVersion = opts.PreviousVersion.ToString()
+		if opts.CompareURL != "" {
+			prev, tag := releaseTags(newVersion, opts)
+			data.CompareURL = fmt.Sprintf(opts.CompareURL, prev, tag)
+		}
+	}
+	if opts.Contributors {
+		data.Contributors = data.Authors
+	}
+	for _, st := range sectionTitles {
+		if len(groupedCommits[st.Type]) == 0 {
+			continue
+		}
+		data.Sections = append(data.Sections, Section{
+			Type:    st.Type,
+			Title:   st.Title,
+			Commits: groupedCommits[st.Type],
+		})
+	}
+
+	return data
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Render renders the changelog template for the given commits and version.
+// The result contains the changelog header followed by the new release
+// section.
+func Render(commits []*git.Commit, newVersion *semver.Version, opts *Opts) ([]byte, error) {
+	if opts == nil {
+		opts = &Opts{}
+	}
+
+	source := changelogTemplate
+	if opts.Format == FormatKeepAChangelog {
+		source = keepAChangelogTemplate
+	}
+	if opts.Template != "" {
+		source = opts.Template
+	}
 
 	var b bytes.Buffer
 
-	tpl, err := template.New("changelog").Parse(changelogTemplate)
+	tpl, err := template.New("changelog").Funcs(templateFuncs(opts)).Parse(source)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if err := tpl.Execute(&b, ChangelogTpl{
-		Version: newVersion.ToString(),
-		Date:    now().Format("2006-01-02"),
-		Commits: groupedCommits,
-	}); err != nil {
-		return err
+	if err := tpl.Execute(&b, NewChangelogTpl(commits, newVersion, opts)); err != nil {
+		return nil, err
 	}
 
-	// NOTE(joel): Read the current changelog file (if any)
-	if _, err := os.Stat(filepath); err == nil {
-		oldChangelog, err := os.ReadFile(filepath)
-		if err != nil {
-			return err
-		}
-		// NOTE(joel): Append the old changelog to the new one (and remove the
-		// header if it exists)
-		idx := bytes.Index(oldChangelog, []byte("# Changelog\n"))
-		if idx == -1 {
-			idx = 0
-		} else {
-			idx += len("# Changelog\n")
+	return b.Bytes(), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// releaseTags returns the tag names of the previous and the new release. The
+// previous tag keeps its original name (empty if there is no previous
+// release). The new tag defaults to `v<version>`.
+func releaseTags(newVersion *semver.Version, opts *Opts) (string, string) {
+	prev := ""
+	if opts.PreviousVersion != nil {
+		prev = opts.PreviousVersion.Original
+		if prev == "" {
+			prev = "v" + opts.PreviousVersion.ToString()
 		}
-		_, err = b.Write(oldChangelog[idx:])
-		if err != nil {
-			return err
+	}
+	tag := opts.Tag
+	if tag == "" {
+		tag = "v" + newVersion.ToString()
+	}
+	return prev, tag
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// uniqueAuthors returns the unique authors and co-authors of the given
+// commits sorted by name. Names and emails are expected to be mapped with the
+// `.mailmap` already. People are considered the same if either their name or
+// their email matches (case-insensitive), so that one person with two emails
+// is listed once.
+func uniqueAuthors(commits []*git.Commit) []git.Committer {
+	seenNames := make(map[string]bool)
+	seenEmails := make(map[string]bool)
+	authors := make([]git.Committer, 0)
+	for _, c := range commits {
+		for _, a := range c.Authors() {
+			name, email := strings.ToLower(a.Name), strings.ToLower(a.Email)
+			if name == "" || seenNames[name] || (email != "" && seenEmails[email]) {
+				continue
+			}
+			seenNames[name] = true
+			if email != "" {
+				seenEmails[email] = true
+			}
+			authors = append(authors, a)
 		}
 	}
+	sort.SliceStable(authors, func(i, j int) bool {
+		return authors[i].Name < authors[j].Name
+	})
+	return authors
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// StripHeader strips the changelog header from a rendered changelog and
+// returns only the release section.
+func StripHeader(rendered []byte) []byte {
+	section := rendered
+	if idx := bytes.Index(rendered, []byte(header)); idx != -1 {
+		section = rendered[idx+len(header):]
+	}
+	return bytes.TrimSpace(section)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Prepend puts the rendered release section in front of the old changelog
+// content and returns the new changelog content.
+func Prepend(rendered []byte, oldChangelog []byte) []byte {
+	var b bytes.Buffer
+	b.Write(rendered)
 
-	// NOTE(joel): Write the new changelog file (or overwrite the current one)
-	if err := os.WriteFile(filepath, b.Bytes(), 0644); err != nil {
-		return err
+	// NOTE(joel): Append the old changelog to the new one (and remove the
+	// header if it exists)
+	idx := bytes.Index(oldChangelog, []byte(header))
+	if idx == -1 {
+		idx = 0
+	} else {
+		idx += len(header)
 	}
+	b.Write(oldChangelog[idx:])
 
-	return nil
+	return b.Bytes()
 }
@@ -1,6 +1,6 @@
 # Changelog
 
-## {{ .Version }} - {{ .Date }}{{"\n"}}
+## {{ if .CompareURL }}[{{ .Version }}]({{ .CompareURL }}){{ else }}{{ .Version }}{{ end }} - {{ .Date }}{{"\n"}}
 
 {{- if eq (len .Commits) 0 }}
 - No changes
@@ -9,27 +9,39 @@
 {{- if (index .Commits 0) }}
 ### BREAKING CHANGES
 {{- range (index .Commits 0) }}
-- {{ if .Scope -}} **{{ .Scope }}:** {{- else -}} {{ .Type }}: {{- end }} {{ .Message }} ({{ .Sha.Short }})
+- {{ if .Scope -}} **{{ .Scope }}:** {{- else -}} {{ .Type }}: {{- end }} {{ issueLink .Message }} ({{ commitLink .Sha }})
+{{- with .References }}, closes {{ issueLink (join ", " .) }}{{ end }}
+{{- if .BreakingChange }}{{ "\n" }}{{ indent 2 .BreakingChange }}{{ end }}
 {{- end }}
 {{ end }}
 
 {{- if (index .Commits 1) }}
 ### Features
 {{- range (index .Commits 1) }}
-- {{ if .Scope -}} **{{ .Scope }}:** {{ end -}} {{ .Message }} ({{ .Sha.Short }})
+- {{ if .Scope -}} **{{ .Scope }}:** {{ end -}} {{ issueLink .Message }} ({{ commitLink .Sha }})
+{{- with .References }}, closes {{ issueLink (join ", " .) }}{{ end }}
 {{- end }}
 {{ end }}
 
 {{- if (index .Commits 2) }}
 ### Bug Fixes
 {{- range (index .Commits 2) }}
-- {{ if .Scope -}} **{{ .Scope }}:** {{ end -}} {{ .Message }} ({{ .Sha.Short }})
+- {{ if .Scope -}} **{{ .Scope }}:** {{ end -}} {{ issueLink .Message }} ({{ commitLink .Sha }})
+{{- with .References }}, closes {{ issueLink (join ", " .) }}{{ end }}
 {{- end }}
 {{ end }}
 
 {{- if (index .Commits 3) }}
 ### Miscellaneous
 {{- range (index .Commits 3) }}
-- {{ if .Scope -}} **{{ .Scope }}:** {{- else -}} {{ .Type }}: {{- end }} {{ .Message }} ({{ .Sha.Short }})
+- {{ if .Scope -}} **{{ .Scope }}:** {{- else -}} {{ .Type }}: {{- end }} {{ issueLink .Message }} ({{ commitLink .Sha }})
+{{- with .References }}, closes {{ issueLink (join ", " .) }}{{ end }}
+{{- end }}
+{{ end }}
+
+{{- if .Contributors }}
+### Contributors
+{{- range .Contributors }}
+- {{ .Name }}
 {{- end }}
 {{ end }}
\ No newline at end of file
@@ -1,8 +1,6 @@
 package changelog
 
 import (
-	"os"
-	"path"
 	"testing"
 	"time"
 
@@ -13,46 +11,10 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func createTmpDir(t *testing.T) (string, func()) {
-	tempDir, err := os.MkdirTemp("", "git-repo-*")
-	if err != nil {
-		t.Fatalf("Error creating temp directory: %v", err)
-	}
-
-	cleanUpFunc := func() {
-		os.RemoveAll(tempDir)
-	}
-
-	return tempDir, cleanUpFunc
-}
-
-func prepareChangelog(t *testing.T, changelogStr string) (string, func()) {
-	tempDir, cleanUpFunc := createTmpDir(t)
-
-	err := os.WriteFile(tempDir+"/CHANGELOG.md", []byte(changelogStr), 0644)
-	if err != nil {
-		t.Fatalf("Error preparing changelog: %v", err)
-	}
-
-	return tempDir, cleanUpFunc
-}
-
-func assertFileContent(t *testing.T, path string, expected string) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		t.Fatalf("Error reading file: %v", err)
-	}
-
-	assert.Equal(t, content, []byte(expected))
-}
-
 ////////////////////////////////////////////////////////////////////////////////
 
-func TestGenerate(t *testing.T) {
-	tempDir, cleanUp := prepareChangelog(
-		t, "# Changelog\n\n## 0.0.0 - 2006-01-02\n\nSome old content\n",
-	)
-	defer cleanUp()
+func TestRenderMerge(t *testing.T) {
+	old := []byte("# Changelog\n\n## 0.0.0 - 2006-01-02\n\nSome old content\n")
 
 	// NOTE(joel): Mock time
 	now = func() time.Time {
@@ -65,11 +27,12 @@ func TestGenerate(t *testing.T) {
 				Short: "1234567",
 				Long:  "1234567891234567891234567891234567891234",
 			},
-			Message:  "some breaking change",
-			Body:     "BREAKING CHANGE: some breaking change\nWith a linebreak",
-			Breaking: true,
-			Type:     "feat",
-			Scope:    "",
+			Message:        "some breaking change",
+			Body:           "BREAKING CHANGE: some breaking change\nWith a linebreak",
+			BreakingChange: "some breaking change\nWith a linebreak",
+			Breaking:       true,
+			Type:           "feat",
+			Scope:          "",
 		},
 		{
 			Sha: git.Sha{
@@ -124,17 +87,17 @@ func TestGenerate(t *testing.T) {
 	}
 	newVersion, _ := semver.Parse("1.0.0")
 
-	filepath := path.Join(tempDir, "./CHANGELOG.md")
-	err := Generate(commits, newVersion, filepath)
-
+	rendered, err := Render(commits, newVersion, nil)
 	require.NoError(t, err)
-	require.FileExists(t, filepath)
-	assertFileContent(t, filepath, `# Changelog
+	merged, _ := Merge(rendered, old, newVersion, nil)
+	assert.Equal(t, `# Changelog
 
 ## 1.0.0 - 2006-01-02
 
 ### BREAKING CHANGES
 - feat: some breaking change (1234567)
+  some breaking change
+  With a linebreak
 
 ### Features
 - some feature (2234567)
@@ -150,12 +113,11 @@ func TestGenerate(t *testing.T) {
 ## 0.0.0 - 2006-01-02
 
 Some old content
-`)
+`, string(merged))
 }
 
-func TestGenerateNoPrevContent(t *testing.T) {
-	tempDir, cleanUp := prepareChangelog(t, "")
-	defer cleanUp()
+func TestRenderMergeNoPrevContent(t *testing.T) {
+	old := []byte("")
 
 	// NOTE(joel): Mock time
 	now = func() time.Time {
@@ -227,12 +189,10 @@ func TestGenerateNoPrevContent(t *testing.T) {
 	}
 	newVersion, _ := semver.Parse("1.0.0")
 
-	filepath := path.Join(tempDir, "./CHANGELOG.md")
-	err := Generate(commits, newVersion, filepath)
-
+	rendered, err := Render(commits, newVersion, nil)
 	require.NoError(t, err)
-	require.FileExists(t, filepath)
-	assertFileContent(t, filepath, `# Changelog
+	merged, _ := Merge(rendered, old, newVersion, nil)
+	assert.Equal(t, `# Changelog
 
 ## 1.0.0 - 2006-01-02
 
@@ -249,14 +209,11 @@ func TestGenerateNoPrevContent(t *testing.T) {
 ### Miscellaneous
 - chore: some chore (5234567)
 - docs: some change of documentation (6234567)
-`)
+`, string(merged))
 }
 
-func TestGenerateNoCommits(t *testing.T) {
-	tempDir, cleanUp := prepareChangelog(
-		t, "# Changelog\n\n## 0.0.0 - 2006-01-02\n\nSome old content\n",
-	)
-	defer cleanUp()
+func TestRenderMergeNoCommits(t *testing.T) {
+	old := []byte("# Changelog\n\n## 0.0.0 - 2006-01-02\n\nSome old content\n")
 
 	// NOTE(joel): Mock time
 	now = func() time.Time {
@@ -266,12 +223,10 @@ func TestGenerateNoCommits(t *testing.T) {
 	commits := make([]*git.Commit, 0)
 	newVersion, _ := semver.Parse("1.0.0")
 
-	filepath := path.Join(tempDir, "./CHANGELOG.md")
-	err := Generate(commits, newVersion, filepath)
-
+	rendered, err := Render(commits, newVersion, nil)
 	require.NoError(t, err)
-	require.FileExists(t, filepath)
-	assertFileContent(t, filepath, `# Changelog
+	merged, _ := Merge(rendered, old, newVersion, nil)
+	assert.Equal(t, `# Changelog
 
 ## 1.0.0 - 2006-01-02
 
@@ -280,14 +235,11 @@ func TestGenerateNoCommits(t *testing.T) {
 ## 0.0.0 - 2006-01-02
 
 Some old content
-`)
+`, string(merged))
 }
 
-func TestGenerateChoreCommits(t *testing.T) {
-	tempDir, cleanUp := prepareChangelog(
-		t, "# Changelog\n\n## 0.0.0 - 2006-01-02\n\nSome old content\n",
-	)
-	defer cleanUp()
+func TestRenderMergeChoreCommits(t *testing.T) {
+	old := []byte("# Changelog\n\n## 0.0.0 - 2006-01-02\n\nSome old content\n")
 
 	// NOTE(joel): Mock time
 	now = func() time.Time {
@@ -308,12 +260,10 @@ func TestGenerateChoreCommits(t *testing.T) {
 	}
 	newVersion, _ := semver.Parse("1.0.0")
 
-	filepath := path.Join(tempDir, "./CHANGELOG.md")
-	err := Generate(commits, newVersion, filepath)
-
+	rendered, err := Render(commits, newVersion, nil)
 	require.NoError(t, err)
-	require.FileExists(t, filepath)
-	assertFileContent(t, filepath, `# Changelog
+	merged, _ := Merge(rendered, old, newVersion, nil)
+	assert.Equal(t, `# Changelog
 
 ## 1.0.0 - 2006-01-02
 
@@ -323,11 +273,10 @@ func TestGenerateChoreCommits(t *testing.T) {
 ## 0.0.0 - 2006-01-02
 
 Some old content
-`)
+`, string(merged))
 }
-func TestGenerateNoPrevChangelog(t *testing.T) {
-	tempDir, cleanUp := createTmpDir(t)
-	defer cleanUp()
+func TestRenderMergeNoPrevChangelog(t *testing.T) {
+	var old []byte
 
 	// NOTE(joel): Mock time
 	now = func() time.Time {
@@ -348,16 +297,205 @@ func TestGenerateNoPrevChangelog(t *testing.T) {
 	}
 	newVersion, _ := semver.Parse("1.0.0")
 
-	filepath := path.Join(tempDir, "./CHANGELOG.md")
-	err := Generate(commits, newVersion, filepath)
-
+	rendered, err := Render(commits, newVersion, nil)
 	require.NoError(t, err)
-	require.FileExists(t, filepath)
-	assertFileContent(t, filepath, `# Changelog
+	merged, _ := Merge(rendered, old, newVersion, nil)
+	assert.Equal(t, `# Changelog
 
 ## 1.0.0 - 2006-01-02
 
 ### Miscellaneous
 - chore: some chore (5234567)
-`)
+`, string(merged))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestRenderCustomTemplate(t *testing.T) {
+	// NOTE(joel): Mock time
+	now = func() time.Time {
+		return time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	}
+
+	commits := []*git.Commit{
+		{
+			Sha:     git.Sha{Short: "1234567"},
+			Author:  git.Committer{Name: "Jane Doe", Email: "jane@example.com"},
+			Message: "add feature (#12)",
+			Type:    "feat",
+		},
+		{
+			Sha:     git.Sha{Short: "2234567"},
+			Author:  git.Committer{Name: "John Doe", Email: "john@example.com"},
+			Message: "update dependencies",
+			Type:    "chore",
+		},
+		{
+			Sha:     git.Sha{Short: "3234567"},
+			Author:  git.Committer{Name: "Jane Doe", Email: "jane@example.com"},
+			Message: "fix a very long bug description",
+			Type:    "fix",
+		},
+	}
+	newVersion, _ := semver.Parse("1.1.0")
+	prevVersion, _ := semver.Parse("v1.0.0")
+
+	tpl := `# Changelog
+
+## {{ .Version }} (from {{ .PreviousVersion }}) - {{ .Date }}
+{{ range .Sections }}
+### {{ title (lower .Title) }}
+{{- range .Commits }}
+- {{ truncate 20 .Message | issueLink }} ({{ .Sha.Short }})
+{{- end }}
+{{ end }}
+Thanks to {{ range $i, $a := .Authors }}{{ if $i }}, {{ end }}{{ $a.Name }}{{ end }}
+`
+
+	rendered, err := Render(commits, newVersion, &Opts{
+		Template:        tpl,
+		PreviousVersion: prevVersion,
+		IssueURL:        "https://example.com/issues/%s",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `# Changelog
+
+## 1.1.0 (from 1.0.0) - 2006-01-02
+
+### Features
+- add feature ([#12](https://example.com/issues/12)) (1234567)
+
+### Bug Fixes
+- fix a very long bug… (3234567)
+
+### Miscellaneous
+- update dependencies (2234567)
+
+Thanks to Jane Doe, John Doe
+`, string(rendered))
+}
+
+func TestRenderContributors(t *testing.T) {
+	// NOTE(joel): Mock time
+	now = func() time.Time {
+		return time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	}
+
+	bot := git.Committer{Name: "Forge Bot", Email: "bot@example.com"}
+	commits := []*git.Commit{
+		{
+			Sha:       git.Sha{Short: "1234567"},
+			Author:    git.Committer{Name: "Jane Doe", Email: "jane@example.com"},
+			Committer: bot,
+			CoAuthors: []git.Committer{{Name: "Max", Email: "max@example.com"}},
+			Message:   "add feature",
+			Type:      "feat",
+		},
+		{
+			Sha:       git.Sha{Short: "2234567"},
+			Author:    git.Committer{Name: "Jane Doe", Email: "jane@work.example.com"},
+			Committer: bot,
+			Message:   "fix bug",
+			Type:      "fix",
+		},
+		{
+			Sha:       git.Sha{Short: "3234567"},
+			Author:    git.Committer{Name: "J. Doe", Email: "JANE@example.com"},
+			Committer: bot,
+			Message:   "fix another bug",
+			Type:      "fix",
+		},
+	}
+	newVersion, _ := semver.Parse("1.1.0")
+
+	rendered, err := Render(commits, newVersion, &Opts{Contributors: true})
+	require.NoError(t, err)
+	assert.Equal(t, `# Changelog
+
+## 1.1.0 - 2006-01-02
+
+### Features
+- add feature (1234567)
+
+### Bug Fixes
+- fix bug (2234567)
+- fix another bug (3234567)
+
+### Contributors
+- Jane Doe
+- Max
+`, string(rendered))
+
+	rendered, err = Render(commits, newVersion, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, string(rendered), "Contributors")
+}
+
+func TestRenderLinks(t *testing.T) {
+	// NOTE(joel): Mock time
+	now = func() time.Time {
+		return time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	}
+
+	commits := []*git.Commit{
+		{
+			Sha:      git.Sha{Short: "1234567", Long: "1234567891234567891234567891234567891234"},
+			Message:  "add feature (#12)",
+			Type:     "feat",
+			Trailers: []git.Trailer{{Key: "Closes", Value: "#34"}, {Key: "Refs", Value: "#35"}},
+		},
+	}
+	newVersion, _ := semver.Parse("1.1.0")
+	prevVersion, _ := semver.Parse("v1.0.0")
+
+	rendered, err := Render(commits, newVersion, &Opts{
+		PreviousVersion: prevVersion,
+		IssueURL:        "https://example.com/o/r/issues/%s",
+		CommitURL:       "https://example.com/o/r/commit/%s",
+		CompareURL:      "https://example.com/o/r/compare/%s...%s",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `# Changelog
+
+## [1.1.0](https://example.com/o/r/compare/v1.0.0...v1.1.0) - 2006-01-02
+
+### Features
+- add feature ([#12](https://example.com/o/r/issues/12)) ([1234567](https://example.com/o/r/commit/1234567891234567891234567891234567891234)), closes [#34](https://example.com/o/r/issues/34), [#35](https://example.com/o/r/issues/35)
+`, string(rendered))
+}
+
+func TestNewChangelogTplRules(t *testing.T) {
+	commits := []*git.Commit{
+		{Type: "feat", Message: "some feature"},
+		{Type: "perf", Message: "some improvement"},
+		{Type: "fix", Message: "some fix"},
+	}
+	newVersion, _ := semver.Parse("2.0.0")
+
+	// NOTE(joel): Commits are grouped by the rules, but no commit is listed
+	// under BREAKING CHANGES unless it is breaking.
+	data := NewChangelogTpl(commits, newVersion, &Opts{
+		Rules: git.DefaultRules.Merge(git.Rules{
+			"feat": semver.ReleaseTypeMajor,
+			"perf": semver.ReleaseTypePatch,
+		}),
+	})
+	require.Len(t, data.Sections, 2)
+	assert.Equal(t, "Features", data.Sections[0].Title)
+	assert.Equal(t, []*git.Commit{commits[0]}, data.Sections[0].Commits)
+	assert.Equal(t, "Bug Fixes", data.Sections[1].Title)
+	assert.Equal(t, []*git.Commit{commits[1], commits[2]}, data.Sections[1].Commits)
+	assert.Empty(t, data.Commits[git.CommitTypeBreaking])
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestRenderInvalidTemplate(t *testing.T) {
+	newVersion, _ := semver.Parse("1.0.0")
+
+	_, err := Render(nil, newVersion, &Opts{Template: "{{ .Version "})
+	assert.Error(t, err)
+
+	_, err = Render(nil, newVersion, &Opts{Template: "{{ .Unknown }}"})
+	assert.Error(t, err)
 }
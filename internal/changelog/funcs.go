@@ -0,0 +1,116 @@
+package changelog
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/joelvoss/release-lit/internal/git"
+)
+
+var issueRegexp *regexp.Regexp
+
+func init() {
+	issueRegexp = regexp.MustCompile(`(^|[^\w&/\[])#(\d+)\b`)
+}
+
+// templateFuncs returns the helper functions available in changelog
+// templates.
+func templateFuncs(opts *Opts) template.FuncMap {
+	return template.FuncMap{
+		"title":      title,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trim":       strings.TrimSpace,
+		"truncate":   truncate,
+		"indent":     indent,
+		"join":       join,
+		"issueLink":  func(s string) string { return issueLink(opts.IssueURL, s) },
+		"commitLink": func(sha git.Sha) string { return commitLink(opts.CommitURL, sha) },
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// title upper-cases the first letter of every word in s.
+func title(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		defer func() { prev = r }()
+		if unicode.IsSpace(prev) {
+			return unicode.ToTitle(r)
+		}
+		return r
+	}, s)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// truncate shortens s to at most n characters. If s is truncated, the last
+// character is replaced with an ellipsis.
+func truncate(n int, s string) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// indent prefixes every non-empty line of s with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// join joins the elements of the given slice with sep. Elements that aren't
+// strings are formatted with fmt.Sprint.
+func join(sep string, items any) (string, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: expected a list, got %T", items)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// issueLink replaces issue references like `#123` in s with Markdown links to
+// the issue. If no issue URL is configured, s is returned unchanged.
+func issueLink(issueURL string, s string) string {
+	if issueURL == "" {
+		return s
+	}
+	return issueRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		sub := issueRegexp.FindStringSubmatch(m)
+		return fmt.Sprintf("%s[#%s](%s)", sub[1], sub[2], fmt.Sprintf(issueURL, sub[2]))
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// commitLink renders the short sha as Markdown link to the commit. If no
+// commit URL is configured, the short sha is returned.
+func commitLink(commitURL string, sha git.Sha) string {
+	if commitURL == "" || sha.Long == "" {
+		return sha.Short
+	}
+	return fmt.Sprintf("[%s](%s)", sha.Short, fmt.Sprintf(commitURL, sha.Long))
+}
@@ -0,0 +1,66 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTitle(t *testing.T) {
+	assert.Equal(t, "Bug Fixes", title("bug fixes"))
+	assert.Equal(t, "BREAKING CHANGES", title("BREAKING CHANGES"))
+	assert.Equal(t, "", title(""))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate(10, "short"))
+	assert.Equal(t, "exactly10!", truncate(10, "exactly10!"))
+	assert.Equal(t, "some long…", truncate(10, "some long text"))
+	assert.Equal(t, "äöü…", truncate(4, "äöüäöü"))
+	assert.Equal(t, "unlimited", truncate(0, "unlimited"))
+}
+
+func TestIndent(t *testing.T) {
+	assert.Equal(t, "  one\n\n  two", indent(2, "one\n\ntwo"))
+	assert.Equal(t, "one", indent(0, "one"))
+}
+
+func TestJoin(t *testing.T) {
+	got, err := join(", ", []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, "a, b, c", got)
+
+	got, err = join(" | ", []git.CommitType{git.CommitTypeFeat, git.CommitTypeFix})
+	require.NoError(t, err)
+	assert.Equal(t, "1 | 2", got)
+
+	_, err = join(", ", "not a list")
+	assert.EqualError(t, err, "join: expected a list, got string")
+}
+
+func TestIssueLink(t *testing.T) {
+	url := "https://example.com/issues/%s"
+
+	assert.Equal(t, "fix (#1)", issueLink("", "fix (#1)"))
+	assert.Equal(t, "[#1](https://example.com/issues/1)", issueLink(url, "#1"))
+	assert.Equal(
+		t,
+		"fix ([#12](https://example.com/issues/12)), closes [#3](https://example.com/issues/3)",
+		issueLink(url, "fix (#12), closes #3"),
+	)
+	// NOTE(joel): Don't link HTML entities, anchors or existing links.
+	assert.Equal(t, "&#123; a/#1 [#2](x)", issueLink(url, "&#123; a/#1 [#2](x)"))
+}
+
+func TestCommitLink(t *testing.T) {
+	sha := git.Sha{Short: "1234567", Long: "1234567890"}
+	assert.Equal(t, "1234567", commitLink("", sha))
+	assert.Equal(
+		t,
+		"[1234567](https://example.com/commit/1234567890)",
+		commitLink("https://example.com/commit/%s", sha),
+	)
+}
@@ -0,0 +1,333 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/semver"
+)
+
+const keepAChangelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+const unreleasedLabel = "Unreleased"
+
+var unreleasedRegexp, linkRefRegexp *regexp.Regexp
+
+func init() {
+	unreleasedRegexp = regexp.MustCompile(`(?i)^##[ \t]+\[?unreleased\]?`)
+	linkRefRegexp = regexp.MustCompile(`^\[([^\]]+)\]:[ \t]*(\S+)`)
+}
+
+// Category is a group of commits of a release in the Keep a Changelog
+// format, e.g. `Added`.
+type Category struct {
+	Title   string
+	Commits []*git.Commit
+}
+
+// NOTE(joel): Keep a Changelog categories in display order.
+var categoryTitles = []string{
+	"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security",
+}
+
+// NOTE(joel): Commit types that map to a category. Commits of other types
+// are listed under `Changed` if they trigger a release and omitted otherwise.
+var categoryTypes = map[string]string{
+	"feat":      "Added",
+	"fix":       "Fixed",
+	"perf":      "Changed",
+	"refactor":  "Changed",
+	"deprecate": "Deprecated",
+	"remove":    "Removed",
+	"security":  "Security",
+}
+
+type linkRef struct {
+	label string
+	url   string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// groupByCategory groups the commits into Keep a Changelog categories and
+// returns the non-empty ones in display order.
+func groupByCategory(commits []*git.Commit, rules git.Rules) []Category {
+	grouped := make(map[string][]*git.Commit)
+	for _, c := range commits {
+		title := categoryOf(c, rules)
+		if title == "" {
+			continue
+		}
+		grouped[title] = append(grouped[title], c)
+	}
+
+	categories := make([]Category, 0)
+	for _, title := range categoryTitles {
+		if len(grouped[title]) > 0 {
+			categories = append(categories, Category{Title: title, Commits: grouped[title]})
+		}
+	}
+	return categories
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// categoryOf returns the Keep a Changelog category of the commit or an empty
+// string if the commit isn't notable.
+func categoryOf(c *git.Commit, rules git.Rules) string {
+	if c.Scope == "security" {
+		return "Security"
+	}
+	if title, ok := categoryTypes[c.Type]; ok {
+		return title
+	}
+	if rules.ReleaseType(c) != semver.ReleaseTypeNone {
+		return "Changed"
+	}
+	return ""
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// mergeKeepAChangelog puts the rendered release section below the
+// `[Unreleased]` section of the old changelog. Hand-written entries of the
+// `[Unreleased]` section are moved into the new release, an existing section
+// of the same version is replaced (keeping its hand-written entries) and the
+// link reference definitions at the bottom are updated. Reports whether an
+// existing section was replaced.
+func mergeKeepAChangelog(rendered []byte, oldChangelog []byte, v *semver.Version, opts *Opts) ([]byte, bool) {
+	content, links := splitLinkRefs(oldChangelog)
+	if len(bytes.TrimSpace(content)) == 0 {
+		content = []byte(keepAChangelogHeader)
+	}
+
+	// NOTE(joel): Remove the section of the same version (if any) and the
+	// `[Unreleased]` section. Its entries are moved into the new release. The
+	// hand-written entries of the removed section were moved there by an
+	// earlier run, so they are kept as well.
+	generated := string(StripHeader(rendered))
+	replaced := false
+	var handWritten []string
+	if existing := FindSection(content, v); existing != nil {
+		_, body, _ := strings.Cut(string(content[existing.Start:existing.End]), "\n")
+		handWritten = append(handWritten, withoutGenerated(body, generated))
+		content = slices.Concat(content[:existing.Start], content[existing.End:])
+		replaced = true
+	}
+	if start, end := findUnreleased(content); start != -1 {
+		_, body, _ := strings.Cut(string(content[start:end]), "\n")
+		handWritten = append(handWritten, body)
+		content = slices.Concat(content[:start], content[end:])
+	}
+	unreleased := strings.TrimSpace(strings.Join(handWritten, "\n"))
+
+	// NOTE(joel): Everything before the first level 2 heading is the intro
+	// (title and description), everything after are older releases.
+	intro, rest := content, []byte{}
+	if idx := headingRegexp.FindIndex(content); idx != nil {
+		intro, rest = content[:idx[0]], content[idx[0]:]
+	}
+
+	section := mergeEntries(generated, unreleased)
+
+	var b strings.Builder
+	b.Write(bytes.TrimRight(intro, "\n"))
+	b.WriteString("\n\n## [" + unreleasedLabel + "]\n\n")
+	b.WriteString(section)
+	b.WriteString("\n")
+	if rest = bytes.TrimSpace(rest); len(rest) > 0 {
+		b.WriteString("\n")
+		b.Write(rest)
+		b.WriteString("\n")
+	}
+
+	merged := []byte(b.String())
+	links = updateLinkRefs(links, merged, v, opts)
+	if len(links) > 0 {
+		b.WriteString("\n")
+		for _, l := range links {
+			b.WriteString(fmt.Sprintf("[%s]: %s\n", l.label, l.url))
+		}
+	}
+
+	return []byte(b.String()), replaced
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// withoutGenerated returns the body of a release section without the entries
+// of the generated section, i.e. only the hand-written entries. Category
+// headings are kept, so that the entries stay in their category.
+func withoutGenerated(body string, generated string) string {
+	lines := strings.Split(generated, "\n")
+	kept := make([]string, 0)
+	for _, line := range strings.Split(body, "\n") {
+		if !strings.HasPrefix(line, "### ") && slices.Contains(lines, line) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// findUnreleased returns the byte offsets of the `[Unreleased]` section or -1
+// if there is none.
+func findUnreleased(content []byte) (int, int) {
+	headings := headingRegexp.FindAllIndex(content, -1)
+	for i, h := range headings {
+		end := len(content)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+		if unreleasedRegexp.Match(content[h[0]:end]) {
+			return h[0], end
+		}
+	}
+	return -1, -1
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// splitLinkRefs splits the link reference definitions (e.g.
+// `[1.0.0]: https://...`) at the bottom of the changelog from its content.
+func splitLinkRefs(content []byte) ([]byte, []linkRef) {
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+
+	end := len(lines)
+	for end > 0 {
+		line := strings.TrimSpace(lines[end-1])
+		if line != "" && !linkRefRegexp.MatchString(line) {
+			break
+		}
+		end--
+	}
+
+	links := make([]linkRef, 0)
+	for _, line := range lines[end:] {
+		if m := linkRefRegexp.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			links = append(links, linkRef{label: m[1], url: m[2]})
+		}
+	}
+	if len(links) == 0 {
+		return content, links
+	}
+	return []byte(strings.Join(lines[:end], "\n") + "\n"), links
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// updateLinkRefs sets the links of the `[Unreleased]` section and the new
+// release and sorts the links in the order of the sections of the changelog.
+// Links of unknown labels are kept at the end.
+func updateLinkRefs(links []linkRef, content []byte, v *semver.Version, opts *Opts) []linkRef {
+	urls := make(map[string]string)
+	for _, l := range links {
+		urls[l.label] = l.url
+	}
+
+	if opts.CompareURL != "" {
+		prev, tag := releaseTags(v, opts)
+		urls[unreleasedLabel] = fmt.Sprintf(opts.CompareURL, tag, "HEAD")
+		if prev != "" {
+			urls[v.ToString()] = fmt.Sprintf(opts.CompareURL, prev, tag)
+		}
+	}
+
+	labels := []string{unreleasedLabel}
+	for _, s := range ParseSections(content) {
+		labels = append(labels, s.Version.ToString())
+	}
+	for _, l := range links {
+		if !slices.Contains(labels, l.label) {
+			labels = append(labels, l.label)
+		}
+	}
+
+	sorted := make([]linkRef, 0, len(urls))
+	for _, label := range labels {
+		if url, ok := urls[label]; ok {
+			sorted = append(sorted, linkRef{label: label, url: url})
+		}
+	}
+	return sorted
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// mergeEntries adds the hand-written entries of the `[Unreleased]` section to
+// the rendered release section. Entries of the same category are merged,
+// hand-written entries first.
+func mergeEntries(section string, unreleased string) string {
+	if unreleased == "" {
+		return section
+	}
+
+	heading, body, _ := strings.Cut(section, "\n")
+	genPreamble, genCategories, genOrder := parseCategories(body)
+	handPreamble, handCategories, handOrder := parseCategories(unreleased)
+
+	order := slices.Clone(categoryTitles)
+	for _, title := range slices.Concat(handOrder, genOrder) {
+		if !slices.Contains(order, title) {
+			order = append(order, title)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(heading)
+	b.WriteString("\n")
+	if preamble := slices.Concat(handPreamble, genPreamble); len(preamble) > 0 {
+		b.WriteString("\n" + strings.Join(preamble, "\n") + "\n")
+	}
+	for _, title := range order {
+		entries := slices.Concat(handCategories[title], genCategories[title])
+		if len(entries) == 0 {
+			continue
+		}
+		b.WriteString("\n### " + title + "\n" + strings.Join(entries, "\n") + "\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// parseCategories parses the body of a release section into the lines before
+// the first category, the lines of every category and the order of the
+// categories.
+func parseCategories(body string) ([]string, map[string][]string, []string) {
+	preamble := make([]string, 0)
+	categories := make(map[string][]string)
+	order := make([]string, 0)
+
+	current := ""
+	for _, line := range strings.Split(body, "\n") {
+		if title, ok := strings.CutPrefix(line, "### "); ok {
+			current = strings.TrimSpace(title)
+			if !slices.Contains(order, current) {
+				order = append(order, current)
+			}
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if current == "" {
+			preamble = append(preamble, line)
+		} else {
+			categories[current] = append(categories[current], line)
+		}
+	}
+	return preamble, categories, order
+}
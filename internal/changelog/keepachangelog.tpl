@@ -0,0 +1,19 @@
+# Changelog
+
+## [{{ .Version }}] - {{ .Date }}{{"\n"}}
+
+{{- range .Categories }}
+### {{ .Title }}
+{{- range .Commits }}
+- {{ if .Breaking }}**BREAKING:** {{ end }}{{ if .Scope }}**{{ .Scope }}:** {{ end }}{{ issueLink .Message }} ({{ commitLink .Sha }})
+{{- with .References }}, closes {{ issueLink (join ", " .) }}{{ end }}
+{{- if .BreakingChange }}{{ "\n" }}{{ indent 2 .BreakingChange }}{{ end }}
+{{- end }}
+{{ end }}
+
+{{- if .Contributors }}
+### Contributors
+{{- range .Contributors }}
+- {{ .Name }}
+{{- end }}
+{{ end }}
\ No newline at end of file
@@ -0,0 +1,248 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keepAChangelogOpts(prev string) *Opts {
+	opts := &Opts{
+		Format:     FormatKeepAChangelog,
+		CompareURL: "https://example.com/o/r/compare/%s...%s",
+	}
+	if prev != "" {
+		opts.PreviousVersion, _ = semver.Parse(prev)
+	}
+	return opts
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestRenderKeepAChangelog(t *testing.T) {
+	// NOTE(joel): Mock time
+	now = func() time.Time {
+		return time.Date(2025, 1, 1, 15, 4, 5, 0, time.UTC)
+	}
+
+	commits := []*git.Commit{
+		{Sha: git.Sha{Short: "1111111"}, Type: "feat", Message: "add export"},
+		{Sha: git.Sha{Short: "2222222"}, Type: "fix", Scope: "security", Message: "escape input"},
+		{Sha: git.Sha{Short: "3333333"}, Type: "fix", Message: "fix crash"},
+		{Sha: git.Sha{Short: "4444444"}, Type: "refactor", Breaking: true, Message: "rename option", BreakingChange: "`foo` is now `bar`"},
+		{Sha: git.Sha{Short: "5555555"}, Type: "chore", Message: "update deps"},
+		{Sha: git.Sha{Short: "6666666"}, Type: "remove", Message: "drop legacy API"},
+	}
+	newVersion, _ := semver.Parse("1.2.0")
+
+	rendered, err := Render(commits, newVersion, keepAChangelogOpts("v1.1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, `# Changelog
+
+## [1.2.0] - 2025-01-01
+
+### Added
+- add export (1111111)
+
+### Changed
+- **BREAKING:** rename option (4444444)
+  `+"`foo` is now `bar`"+`
+
+### Removed
+- drop legacy API (6666666)
+
+### Fixed
+- fix crash (3333333)
+
+### Security
+- **security:** escape input (2222222)
+`, string(rendered))
+}
+
+func TestRenderKeepAChangelogContributors(t *testing.T) {
+	// NOTE(joel): Mock time
+	now = func() time.Time {
+		return time.Date(2025, 1, 1, 15, 4, 5, 0, time.UTC)
+	}
+
+	commits := []*git.Commit{
+		{
+			Sha:     git.Sha{Short: "1111111"},
+			Author:  git.Committer{Name: "Jane Doe", Email: "jane@example.com"},
+			Type:    "feat",
+			Message: "add export",
+		},
+		{
+			Sha:     git.Sha{Short: "2222222"},
+			Author:  git.Committer{Name: "Max", Email: "max@example.com"},
+			Type:    "fix",
+			Message: "fix crash",
+		},
+	}
+	newVersion, _ := semver.Parse("1.2.0")
+
+	opts := keepAChangelogOpts("v1.1.0")
+	opts.Contributors = true
+	rendered, err := Render(commits, newVersion, opts)
+	require.NoError(t, err)
+	assert.Equal(t, `# Changelog
+
+## [1.2.0] - 2025-01-01
+
+### Added
+- add export (1111111)
+
+### Fixed
+- fix crash (2222222)
+
+### Contributors
+- Jane Doe
+- Max
+`, string(rendered))
+}
+
+func TestMergeKeepAChangelog(t *testing.T) {
+	v120, _ := semver.Parse("1.2.0")
+	rendered := []byte("# Changelog\n\n## [1.2.0] - 2025-01-01\n\n### Added\n- add export (1111111)\n\n### Fixed\n- fix crash (3333333)\n")
+
+	old := `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+Hand-written summary.
+
+### Added
+- Documentation site
+
+### Security
+- Rotate signing keys
+
+## [1.1.0] - 2024-12-01
+
+### Fixed
+- old fix (0000000)
+
+[Unreleased]: https://example.com/o/r/compare/v1.1.0...HEAD
+[1.1.0]: https://example.com/o/r/compare/v1.0.0...v1.1.0
+`
+
+	merged, replaced := Merge(rendered, []byte(old), v120, keepAChangelogOpts("v1.1.0"))
+	assert.False(t, replaced)
+	assert.Equal(t, `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+## [1.2.0] - 2025-01-01
+
+Hand-written summary.
+
+### Added
+- Documentation site
+- add export (1111111)
+
+### Fixed
+- fix crash (3333333)
+
+### Security
+- Rotate signing keys
+
+## [1.1.0] - 2024-12-01
+
+### Fixed
+- old fix (0000000)
+
+[Unreleased]: https://example.com/o/r/compare/v1.2.0...HEAD
+[1.2.0]: https://example.com/o/r/compare/v1.1.0...v1.2.0
+[1.1.0]: https://example.com/o/r/compare/v1.0.0...v1.1.0
+`, string(merged))
+
+	// NOTE(joel): Merging again replaces the section instead of duplicating it.
+	// The hand-written entries moved there before are kept.
+	again, replaced := Merge(rendered, merged, v120, keepAChangelogOpts("v1.1.0"))
+	assert.True(t, replaced)
+	assert.Equal(t, 1, countSections(again, "1.2.0"))
+	assert.Equal(t, string(merged), string(again))
+}
+
+func TestMergeKeepAChangelogReplaceSection(t *testing.T) {
+	v120, _ := semver.Parse("1.2.0")
+	old := `# Changelog
+
+## [Unreleased]
+
+### Fixed
+- Typo in the docs
+
+## [1.2.0] - 2025-01-01
+
+Hand-written summary.
+
+### Added
+- Documentation site
+- add export (1111111)
+
+### Fixed
+- fix crash (3333333)
+`
+
+	// NOTE(joel): The section is rendered again with another commit. Entries
+	// of the old section that aren't generated are kept, new entries of the
+	// `[Unreleased]` section are added.
+	rendered := []byte("# Changelog\n\n## [1.2.0] - 2025-01-02\n\n### Added\n- add export (1111111)\n\n### Fixed\n- fix crash (3333333)\n- fix leak (4444444)\n")
+	merged, replaced := Merge(rendered, []byte(old), v120, &Opts{Format: FormatKeepAChangelog})
+	assert.True(t, replaced)
+	assert.Equal(t, `# Changelog
+
+## [Unreleased]
+
+## [1.2.0] - 2025-01-02
+
+Hand-written summary.
+
+### Added
+- Documentation site
+- add export (1111111)
+
+### Fixed
+- Typo in the docs
+- fix crash (3333333)
+- fix leak (4444444)
+`, string(merged))
+}
+
+func TestMergeKeepAChangelogEmpty(t *testing.T) {
+	v100, _ := semver.Parse("1.0.0")
+	rendered := []byte("# Changelog\n\n## [1.0.0] - 2025-01-01\n\n### Added\n- initial release (1111111)\n")
+
+	merged, replaced := Merge(rendered, nil, v100, keepAChangelogOpts(""))
+	assert.False(t, replaced)
+	assert.Equal(t, keepAChangelogHeader+`
+## [Unreleased]
+
+## [1.0.0] - 2025-01-01
+
+### Added
+- initial release (1111111)
+
+[Unreleased]: https://example.com/o/r/compare/v1.0.0...HEAD
+`, string(merged))
+}
+
+func countSections(content []byte, version string) int {
+	count := 0
+	for _, s := range ParseSections(content) {
+		if s.Version.ToString() == version {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,216 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	NotesFormatJSON = "json"
+	NotesFormatYAML = "yaml"
+)
+
+// NotesFormats are the supported formats of the release notes export.
+var NotesFormats = []string{NotesFormatJSON, NotesFormatYAML}
+
+// NOTE(joel): Machine-readable keys of the section types.
+var sectionKeys = map[git.CommitType]string{
+	git.CommitTypeBreaking: "breaking",
+	git.CommitTypeFeat:     "feat",
+	git.CommitTypeFix:      "fix",
+	git.CommitTypeMisc:     "misc",
+}
+
+// Notes are the release notes of a single release in a machine-readable form,
+// e.g. for docs sites or chat bots. They are built from the same data as the
+// changelog (see ChangelogTpl).
+type Notes struct {
+	Version string `json:"version" yaml:"version"`
+	// NOTE(joel): Empty for the first release.
+	PreviousVersion string    `json:"previousVersion" yaml:"previousVersion"`
+	Date            string    `json:"date" yaml:"date"`
+	Bump            NotesBump `json:"bump" yaml:"bump"`
+	// NOTE(joel): Empty if unknown.
+	CompareURL string         `json:"compareUrl,omitempty" yaml:"compareUrl,omitempty"`
+	Sections   []NotesSection `json:"sections" yaml:"sections"`
+	Authors    []NotesPerson  `json:"authors" yaml:"authors"`
+}
+
+// NotesBump describes why the version was bumped.
+type NotesBump struct {
+	// NOTE(joel): Release type (major, minor, patch, none).
+	Type   string `json:"type" yaml:"type"`
+	Reason string `json:"reason" yaml:"reason"`
+	// NOTE(joel): Short shas of the commits that trigger the release type.
+	Commits []string `json:"commits" yaml:"commits"`
+}
+
+// NotesSection is a group of commits of the release notes (see Section).
+type NotesSection struct {
+	// NOTE(joel): One of `breaking`, `feat`, `fix` or `misc`.
+	Type    string        `json:"type" yaml:"type"`
+	Title   string        `json:"title" yaml:"title"`
+	Commits []NotesCommit `json:"commits" yaml:"commits"`
+}
+
+// NotesCommit is a single commit of the release notes.
+type NotesCommit struct {
+	Sha      string `json:"sha" yaml:"sha"`
+	ShortSha string `json:"shortSha" yaml:"shortSha"`
+	Type     string `json:"type" yaml:"type"`
+	Scope    string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Breaking bool   `json:"breaking" yaml:"breaking"`
+	// NOTE(joel): Description of the `BREAKING CHANGE:` footer (if any).
+	BreakingChange string        `json:"breakingChange,omitempty" yaml:"breakingChange,omitempty"`
+	Message        string        `json:"message" yaml:"message"`
+	Authors        []NotesPerson `json:"authors" yaml:"authors"`
+	// NOTE(joel): Issue references, e.g. `#123`.
+	References []string `json:"references,omitempty" yaml:"references,omitempty"`
+	// NOTE(joel): Empty if the commit URL is unknown.
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// NotesPerson is an author or co-author of the release notes.
+type NotesPerson struct {
+	Name  string `json:"name" yaml:"name"`
+	Email string `json:"email" yaml:"email"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NewNotes builds the release notes for the given commits and version.
+// releaseType is the release type the version was bumped with.
+func NewNotes(commits []*git.Commit, newVersion *semver.Version, releaseType int, opts *Opts) *Notes {
+	if opts == nil {
+		opts = &Opts{}
+	}
+	data := NewChangelogTpl(commits, newVersion, opts)
+
+	notes := &Notes{
+		Version:         data.Version,
+		PreviousVersion: data.PreviousVersion,
+		Date:            data.Date,
+		Bump:            newNotesBump(commits, releaseType, opts),
+		CompareURL:      data.CompareURL,
+		Sections:        make([]NotesSection, 0, len(data.Sections)),
+		Authors:         notesPeople(data.Authors),
+	}
+	for _, s := range data.Sections {
+		section := NotesSection{
+			Type:    sectionKeys[s.Type],
+			Title:   s.Title,
+			Commits: make([]NotesCommit, 0, len(s.Commits)),
+		}
+		for _, c := range s.Commits {
+			section.Commits = append(section.Commits, newNotesCommit(c, opts))
+		}
+		notes.Sections = append(notes.Sections, section)
+	}
+	return notes
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// newNotesBump returns the release type and the commits that trigger it.
+func newNotesBump(commits []*git.Commit, releaseType int, opts *Opts) NotesBump {
+	bump := NotesBump{
+		Type:    semver.ReleaseTypeName(releaseType),
+		Commits: make([]string, 0),
+	}
+
+	// NOTE(joel): The commits of a pre-release only cover the changes since
+	// the previous pre-release, while its release type is derived from all
+	// commits since the latest stable tag. The reason therefore names the
+	// release type the given commits trigger themselves.
+	triggered := git.GetNextReleaseType(commits, opts.Rules)
+	if triggered != semver.ReleaseTypeNone {
+		for _, c := range commits {
+			if opts.Rules.ReleaseType(c) == triggered {
+				bump.Commits = append(bump.Commits, c.Sha.Short)
+			}
+		}
+	}
+	triggeredName := semver.ReleaseTypeName(triggered)
+
+	switch {
+	case opts.PreviousVersion == nil:
+		bump.Reason = "First release"
+	case len(bump.Commits) == 0:
+		bump.Reason = "No commits trigger a release"
+	case len(bump.Commits) == 1:
+		bump.Reason = fmt.Sprintf("1 commit triggers a %s release", triggeredName)
+	default:
+		bump.Reason = fmt.Sprintf("%d commits trigger a %s release", len(bump.Commits), triggeredName)
+	}
+	return bump
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// newNotesCommit converts a commit into its release notes representation.
+func newNotesCommit(c *git.Commit, opts *Opts) NotesCommit {
+	commit := NotesCommit{
+		Sha:            c.Sha.Long,
+		ShortSha:       c.Sha.Short,
+		Type:           c.Type,
+		Scope:          c.Scope,
+		Breaking:       c.Breaking,
+		BreakingChange: c.BreakingChange,
+		Message:        c.Message,
+		Authors:        notesPeople(c.Authors()),
+	}
+	if refs := c.References(); len(refs) > 0 {
+		commit.References = refs
+	}
+	if opts.CommitURL != "" {
+		commit.URL = fmt.Sprintf(opts.CommitURL, c.Sha.Long)
+	}
+	return commit
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// notesPeople converts committers into their release notes representation.
+func notesPeople(people []git.Committer) []NotesPerson {
+	converted := make([]NotesPerson, 0, len(people))
+	for _, p := range people {
+		converted = append(converted, NotesPerson{Name: p.Name, Email: p.Email})
+	}
+	return converted
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Marshal encodes the release notes in the given format (see NotesFormats).
+func (n *Notes) Marshal(format string) ([]byte, error) {
+	switch format {
+	case NotesFormatJSON:
+		out, err := json.MarshalIndent(n, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	case NotesFormatYAML:
+		var b bytes.Buffer
+		enc := yaml.NewEncoder(&b)
+		enc.SetIndent(2)
+		if err := enc.Encode(n); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf(
+		"unsupported notes format '%s', must be one of %s",
+		format, strings.Join(NotesFormats, ", "),
+	)
+}
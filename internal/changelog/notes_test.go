@@ -0,0 +1,137 @@
+package changelog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func notesCommits() []*git.Commit {
+	return []*git.Commit{
+		{
+			Sha:     git.Sha{Short: "1234567", Long: "1234567891234567891234567891234567891234"},
+			Author:  git.Committer{Name: "Jane", Email: "jane@example.com"},
+			Subject: "feat(api): add endpoint",
+			Body:    "Closes #12\nCo-authored-by: Bob <bob@example.com>",
+			Type:    "feat",
+			Scope:   "api",
+			Message: "add endpoint",
+			Trailers: []git.Trailer{
+				{Key: "Closes", Value: "#12"},
+				{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+			},
+			CoAuthors: []git.Committer{{Name: "Bob", Email: "bob@example.com"}},
+		},
+		{
+			Sha:     git.Sha{Short: "2234567", Long: "2234567891234567891234567891234567891234"},
+			Author:  git.Committer{Name: "Jane", Email: "jane@example.com"},
+			Subject: "fix: some fix",
+			Type:    "fix",
+			Message: "some fix",
+		},
+		{
+			Sha:     git.Sha{Short: "3234567", Long: "3234567891234567891234567891234567891234"},
+			Author:  git.Committer{Name: "Alice", Email: "alice@example.com"},
+			Subject: "feat: another feature",
+			Type:    "feat",
+			Message: "another feature",
+		},
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestNewNotes(t *testing.T) {
+	v, _ := semver.Parse("1.3.0")
+	prev, _ := semver.Parse("v1.2.0")
+	opts := &Opts{
+		PreviousVersion: prev,
+		Date:            time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		CommitURL:       "https://github.com/owner/repo/commit/%s",
+		CompareURL:      "https://github.com/owner/repo/compare/%s...%s",
+	}
+
+	notes := NewNotes(notesCommits(), v, semver.ReleaseTypeMinor, opts)
+
+	assert.Equal(t, "1.3.0", notes.Version)
+	assert.Equal(t, "1.2.0", notes.PreviousVersion)
+	assert.Equal(t, "2006-01-02", notes.Date)
+	assert.Equal(t, "https://github.com/owner/repo/compare/v1.2.0...v1.3.0", notes.CompareURL)
+	assert.Equal(t, NotesBump{
+		Type:    "minor",
+		Reason:  "2 commits trigger a minor release",
+		Commits: []string{"1234567", "3234567"},
+	}, notes.Bump)
+
+	require.Len(t, notes.Sections, 2)
+	assert.Equal(t, "feat", notes.Sections[0].Type)
+	assert.Equal(t, "Features", notes.Sections[0].Title)
+	assert.Equal(t, "fix", notes.Sections[1].Type)
+	require.Len(t, notes.Sections[0].Commits, 2)
+	assert.Equal(t, NotesCommit{
+		Sha:      "1234567891234567891234567891234567891234",
+		ShortSha: "1234567",
+		Type:     "feat",
+		Scope:    "api",
+		Message:  "add endpoint",
+		Authors: []NotesPerson{
+			{Name: "Jane", Email: "jane@example.com"},
+			{Name: "Bob", Email: "bob@example.com"},
+		},
+		References: []string{"#12"},
+		URL:        "https://github.com/owner/repo/commit/1234567891234567891234567891234567891234",
+	}, notes.Sections[0].Commits[0])
+
+	assert.Equal(t, []NotesPerson{
+		{Name: "Alice", Email: "alice@example.com"},
+		{Name: "Bob", Email: "bob@example.com"},
+		{Name: "Jane", Email: "jane@example.com"},
+	}, notes.Authors)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestNewNotesFirstRelease(t *testing.T) {
+	v, _ := semver.Parse("1.0.0")
+
+	notes := NewNotes(notesCommits()[1:2], v, semver.ReleaseTypePatch, nil)
+
+	assert.Equal(t, "", notes.PreviousVersion)
+	assert.Equal(t, "", notes.CompareURL)
+	assert.Equal(t, "First release", notes.Bump.Reason)
+	assert.Equal(t, []string{"2234567"}, notes.Bump.Commits)
+	assert.Equal(t, "", notes.Sections[0].Commits[0].URL)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestNotesMarshal(t *testing.T) {
+	v, _ := semver.Parse("1.3.0")
+	prev, _ := semver.Parse("1.2.0")
+	notes := NewNotes(notesCommits(), v, semver.ReleaseTypeMinor, &Opts{PreviousVersion: prev})
+
+	out, err := notes.Marshal(NotesFormatJSON)
+	require.NoError(t, err)
+	var fromJSON map[string]any
+	require.NoError(t, json.Unmarshal(out, &fromJSON))
+	assert.Equal(t, "1.3.0", fromJSON["version"])
+	assert.Equal(t, "1.2.0", fromJSON["previousVersion"])
+	assert.Equal(t, "minor", fromJSON["bump"].(map[string]any)["type"])
+	assert.NotContains(t, fromJSON, "compareUrl")
+
+	out, err = notes.Marshal(NotesFormatYAML)
+	require.NoError(t, err)
+	var fromYAML Notes
+	require.NoError(t, yaml.Unmarshal(out, &fromYAML))
+	assert.Equal(t, *notes, fromYAML)
+
+	_, err = notes.Marshal("xml")
+	assert.EqualError(t, err, "unsupported notes format 'xml', must be one of json, yaml")
+}
@@ -0,0 +1,119 @@
+package changelog
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/joelvoss/release-lit/internal/semver"
+)
+
+var releaseHeadingRegexp, headingRegexp *regexp.Regexp
+
+func init() {
+	// NOTE(joel): Matches release headings like `## 1.2.0 - 2006-01-02`,
+	// `## [1.2.0](https://...) - 2006-01-02` or `## [1.2.0] - 2006-01-02`.
+	releaseHeadingRegexp = regexp.MustCompile(
+		`(?m)^##[ \t]+\[?v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)\]?(?:[ \t(]|$)`,
+	)
+	headingRegexp = regexp.MustCompile(`(?m)^##[ \t]`)
+}
+
+// ReleaseSection is the section of a single release in a changelog. Start and
+// End are byte offsets into the changelog content; the section reaches from
+// its heading up to the next level 2 heading (or the end of the content).
+type ReleaseSection struct {
+	Version *semver.Version
+	Start   int
+	End     int
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ParseSections returns the release sections of the changelog in the order
+// they appear. Headings that don't contain a valid version are skipped.
+func ParseSections(content []byte) []ReleaseSection {
+	headings := headingRegexp.FindAllIndex(content, -1)
+
+	sections := make([]ReleaseSection, 0)
+	for i, h := range headings {
+		end := len(content)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+
+		m := releaseHeadingRegexp.FindSubmatchIndex(content[h[0]:end])
+		if m == nil || m[0] != 0 {
+			continue
+		}
+		v, err := semver.Parse(string(content[h[0]+m[2] : h[0]+m[3]]))
+		if err != nil {
+			continue
+		}
+		sections = append(sections, ReleaseSection{Version: v, Start: h[0], End: end})
+	}
+	return sections
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// FindSection returns the release section of the given version or nil if the
+// changelog doesn't contain one.
+func FindSection(content []byte, v *semver.Version) *ReleaseSection {
+	for _, s := range ParseSections(content) {
+		if s.Version.Equal(v) {
+			return &s
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// SectionContent returns the release section of the given version without
+// surrounding whitespace or nil if the changelog doesn't contain one. Link
+// reference definitions at the bottom of the changelog are not part of the
+// last section.
+func SectionContent(content []byte, v *semver.Version) []byte {
+	s := FindSection(content, v)
+	if s == nil {
+		return nil
+	}
+	section := content[s.Start:s.End]
+	if s.End == len(content) {
+		section, _ = splitLinkRefs(section)
+	}
+	return bytes.TrimSpace(section)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Merge puts the rendered release section into the old changelog content. If
+// the old changelog already contains a section for the version, that section
+// is replaced. Otherwise the rendered section is prepended (see Prepend).
+// Reports whether an existing section was replaced.
+//
+// In the Keep a Changelog format, the `[Unreleased]` section and the link
+// reference definitions are maintained as well (see mergeKeepAChangelog).
+func Merge(rendered []byte, oldChangelog []byte, v *semver.Version, opts *Opts) ([]byte, bool) {
+	if opts != nil && opts.Format == FormatKeepAChangelog {
+		return mergeKeepAChangelog(rendered, oldChangelog, v, opts)
+	}
+
+	existing := FindSection(oldChangelog, v)
+	if existing == nil {
+		return Prepend(rendered, oldChangelog), false
+	}
+
+	var b bytes.Buffer
+	b.Write(oldChangelog[:existing.Start])
+	b.Write(StripHeader(rendered))
+	b.WriteString("\n")
+
+	rest := bytes.TrimLeft(oldChangelog[existing.End:], "\n")
+	if len(rest) > 0 {
+		b.WriteString("\n")
+		b.Write(rest)
+	}
+
+	return b.Bytes(), true
+}
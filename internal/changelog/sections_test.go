@@ -0,0 +1,99 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const existingChangelog = `# Changelog
+
+## [1.1.0](https://example.com/compare/v1.0.0...v1.1.0) - 2006-01-03
+
+### Features
+- old feature (1234567)
+
+## v1.0.0 - 2006-01-02
+
+- No changes
+
+## Notes
+
+Not a release.
+`
+
+func TestParseSections(t *testing.T) {
+	sections := ParseSections([]byte(existingChangelog))
+	require.Len(t, sections, 2)
+
+	assert.Equal(t, "1.1.0", sections[0].Version.ToString())
+	assert.Equal(t, "## [1.1.0]", existingChangelog[sections[0].Start:sections[0].Start+10])
+	assert.Equal(t, "## v1.0.0 - 2006-01-02\n\n- No changes\n\n", existingChangelog[sections[1].Start:sections[1].End])
+
+	assert.Empty(t, ParseSections(nil))
+	assert.Empty(t, ParseSections([]byte("# Changelog\n\n## 1.x - foo\n")))
+}
+
+func TestSectionContent(t *testing.T) {
+	v100, _ := semver.Parse("1.0.0")
+	v200, _ := semver.Parse("2.0.0")
+
+	assert.Equal(t, "## v1.0.0 - 2006-01-02\n\n- No changes", string(SectionContent([]byte(existingChangelog), v100)))
+	assert.Nil(t, SectionContent([]byte(existingChangelog), v200))
+
+	// NOTE(joel): Link reference definitions belong to the whole changelog.
+	content := "# Changelog\n\n## [1.0.0] - 2006-01-02\n\n- No changes\n\n[1.0.0]: https://example.com\n"
+	assert.Equal(t, "## [1.0.0] - 2006-01-02\n\n- No changes", string(SectionContent([]byte(content), v100)))
+}
+
+func TestMerge(t *testing.T) {
+	v110, _ := semver.Parse("1.1.0")
+	v120, _ := semver.Parse("1.2.0")
+
+	// NOTE(joel): An existing section is replaced in place.
+	merged, replaced := Merge(
+		[]byte("# Changelog\n\n## 1.1.0 - 2006-01-04\n\n### Features\n- new feature (2234567)\n"),
+		[]byte(existingChangelog),
+		v110,
+		nil,
+	)
+	assert.True(t, replaced)
+	assert.Equal(t, `# Changelog
+
+## 1.1.0 - 2006-01-04
+
+### Features
+- new feature (2234567)
+
+## v1.0.0 - 2006-01-02
+
+- No changes
+
+## Notes
+
+Not a release.
+`, string(merged))
+
+	// NOTE(joel): Merging twice doesn't duplicate the section.
+	again, replaced := Merge(
+		[]byte("# Changelog\n\n## 1.1.0 - 2006-01-04\n\n### Features\n- new feature (2234567)\n"),
+		merged,
+		v110,
+		nil,
+	)
+	assert.True(t, replaced)
+	assert.Equal(t, string(merged), string(again))
+
+	// NOTE(joel): New versions are prepended.
+	merged, replaced = Merge(
+		[]byte("# Changelog\n\n## 1.2.0 - 2006-01-05\n\n- No changes\n"),
+		[]byte(existingChangelog),
+		v120,
+		nil,
+	)
+	assert.False(t, replaced)
+	assert.Equal(t, "# Changelog\n\n## 1.2.0 - 2006-01-05\n\n- No changes\n\n"+existingChangelog[13:], string(merged))
+}
@@ -0,0 +1,256 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/semver"
+)
+
+// NOTE(joel): Names of the pre-flight checks, used to skip them.
+const (
+	Clean    = "clean"
+	Branch   = "branch"
+	Detached = "detached"
+	Upstream = "upstream"
+	Tag      = "tag"
+	Released = "released"
+)
+
+// Check is a single pre-flight check of the repository. Run returns an error
+// describing the problem if the check fails.
+type Check struct {
+	Name string
+	Run  func(opts *Opts) error
+}
+
+// NOTE(joel): All checks in the order they are run and reported.
+var Checks = []Check{
+	{Name: Clean, Run: checkClean},
+	{Name: Branch, Run: checkBranch},
+	{Name: Detached, Run: checkDetached},
+	{Name: Upstream, Run: checkUpstream},
+	{Name: Tag, Run: checkTag},
+	{Name: Released, Run: checkReleased},
+}
+
+// NOTE(joel): Names of all checks.
+var Names = []string{Clean, Branch, Detached, Upstream, Tag, Released}
+
+// NOTE(joel): Checks of the repository state and checks of the tag of the new
+// release. The former can run before the next version is computed, the latter
+// need its tag.
+var (
+	StateChecks = []string{Clean, Branch, Detached, Upstream}
+	TagChecks   = []string{Tag, Released}
+)
+
+type Opts struct {
+	// NOTE(joel): Root directory of the git repository.
+	RootDir string
+	// NOTE(joel): Tag of the new release, e.g. `v1.2.0`.
+	Tag string
+	// NOTE(joel): Release branches releases may be created from. May be glob
+	// patterns like `release/*`. If empty, all branches are allowed.
+	Branches []string
+	// NOTE(joel): Names of the checks to skip.
+	Skip []string
+}
+
+// Failure is a failed check.
+type Failure struct {
+	Name string
+	Err  error
+}
+
+// Error lists all failed checks.
+type Error struct {
+	Failures []Failure
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("Pre-flight checks failed:\n")
+	for _, f := range e.Failures {
+		msg := strings.ReplaceAll(strings.TrimSpace(f.Err.Error()), "\n", "\n    ")
+		fmt.Fprintf(&b, "  - %s: %s\n", f.Name, msg)
+	}
+	b.WriteString("Fix the problems above or skip single checks with --skip-check <name>")
+	return b.String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Run runs all checks that aren't skipped. Failures don't stop the remaining
+// checks; they are reported together as an *Error.
+func Run(opts *Opts) error {
+	var failures []Failure
+	for _, c := range Checks {
+		if slices.Contains(opts.Skip, c.Name) {
+			continue
+		}
+		if err := c.Run(opts); err != nil {
+			failures = append(failures, Failure{Name: c.Name, Err: err})
+		}
+	}
+	if len(failures) > 0 {
+		return &Error{Failures: failures}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Join combines the failures of the given errors of Run into a single *Error.
+// Nil errors are ignored and other errors are returned as they are.
+func Join(errs ...error) error {
+	var failures []Failure
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var checkErr *Error
+		if !errors.As(err, &checkErr) {
+			return err
+		}
+		failures = append(failures, checkErr.Failures...)
+	}
+	if len(failures) > 0 {
+		return &Error{Failures: failures}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkClean fails if the working tree has uncommitted changes.
+func checkClean(opts *Opts) error {
+	dirty, err := git.GetDirtyPaths(&git.GitOpts{RootDir: opts.RootDir})
+	if err != nil {
+		return err
+	}
+	if len(dirty) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"Working tree has uncommitted changes:\n  %s\nCommit or stash them, or use --allow-dirty",
+		strings.Join(dirty, "\n  "),
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkBranch fails if the current branch is not a release branch.
+func checkBranch(opts *Opts) error {
+	if len(opts.Branches) == 0 {
+		return nil
+	}
+	name, err := git.GetBranch(&git.GitOpts{RootDir: opts.RootDir})
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf(
+			"HEAD is not on a branch. Releases can only be created from %s",
+			strings.Join(opts.Branches, ", "),
+		)
+	}
+	for _, pattern := range opts.Branches {
+		if pattern == name {
+			return nil
+		}
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"Branch '%s' is not configured as a release branch. Releases can only be created from %s",
+		name, strings.Join(opts.Branches, ", "),
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkDetached fails if HEAD is detached.
+func checkDetached(opts *Opts) error {
+	name, err := git.GetBranch(&git.GitOpts{RootDir: opts.RootDir})
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.New("HEAD is detached. Check out the branch to release from")
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkUpstream fails if the current branch is behind its upstream. Only
+// local refs are compared, so the result is as recent as the last fetch.
+func checkUpstream(opts *Opts) error {
+	gitOpts := &git.GitOpts{RootDir: opts.RootDir}
+	name, err := git.GetBranch(gitOpts)
+	if err != nil || name == "" {
+		return err
+	}
+	upstream, err := git.GetUpstream(name, gitOpts)
+	if err != nil || upstream == "" {
+		return err
+	}
+	behind, err := git.CountCommits("HEAD", upstream, gitOpts)
+	if err != nil {
+		return err
+	}
+	if behind > 0 {
+		return fmt.Errorf(
+			"Branch '%s' is %d commit(s) behind '%s'. Pull the changes first",
+			name, behind, upstream,
+		)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkTag fails if the tag of the new release exists already.
+func checkTag(opts *Opts) error {
+	if opts.Tag == "" {
+		return nil
+	}
+	exists, err := git.TagExists(opts.Tag, &git.GitOpts{RootDir: opts.RootDir})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("Tag '%s' already exists", opts.Tag)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkReleased fails if HEAD is already tagged with a release version.
+// Pre-release tags don't count for stable releases, so that a pre-release can
+// be graduated without new commits.
+func checkReleased(opts *Opts) error {
+	tags, err := git.GetTagsAt("HEAD", &git.GitOpts{RootDir: opts.RootDir})
+	if err != nil {
+		return err
+	}
+	stable := true
+	if v, err := semver.Parse(opts.Tag); err == nil {
+		stable = v.Prerelease() == ""
+	}
+	for _, tag := range tags {
+		v, err := semver.Parse(tag)
+		if err != nil || (stable && v.Prerelease() != "") {
+			continue
+		}
+		return fmt.Errorf("HEAD is already released as '%s'", tag)
+	}
+	return nil
+}
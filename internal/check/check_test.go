@@ -0,0 +1,171 @@
+package check
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createGitRepo(t *testing.T) string {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--initial-branch", "main"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test.user@example.com"},
+		{"commit", "--allow-empty", "-m", "feat: commit #1"},
+	} {
+		runGit(t, dir, args...)
+	}
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Error running git %v: %v (%s)", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestRun(t *testing.T) {
+	cwd := createGitRepo(t)
+	runGit(t, cwd, "tag", "v1.0.0")
+	require.NoError(t, os.WriteFile(path.Join(cwd, "a.txt"), []byte("a"), 0644))
+
+	opts := &Opts{RootDir: cwd, Tag: "v1.0.0", Branches: []string{"release/*"}}
+	err := Run(opts)
+	assert.EqualError(t, err, "Pre-flight checks failed:\n"+
+		"  - clean: Working tree has uncommitted changes:\n"+
+		"      ?? a.txt\n"+
+		"    Commit or stash them, or use --allow-dirty\n"+
+		"  - branch: Branch 'main' is not configured as a release branch. Releases can only be created from release/*\n"+
+		"  - tag: Tag 'v1.0.0' already exists\n"+
+		"  - released: HEAD is already released as 'v1.0.0'\n"+
+		"Fix the problems above or skip single checks with --skip-check <name>")
+
+	var checkErr *Error
+	require.ErrorAs(t, err, &checkErr)
+	assert.Len(t, checkErr.Failures, 4)
+
+	opts.Skip = []string{Clean, Branch, Tag, Released}
+	assert.NoError(t, Run(opts))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestJoin(t *testing.T) {
+	state := &Error{Failures: []Failure{{Name: Clean, Err: errors.New("dirty")}}}
+	tag := &Error{Failures: []Failure{{Name: Tag, Err: errors.New("exists")}}}
+
+	assert.NoError(t, Join(nil, nil))
+	assert.Equal(t, state, Join(state, nil))
+	assert.Equal(t, &Error{Failures: slices.Concat(state.Failures, tag.Failures)}, Join(state, tag))
+	assert.EqualError(t, Join(state, errors.New("git failed")), "git failed")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestCheckBranch(t *testing.T) {
+	cwd := createGitRepo(t)
+
+	assert.NoError(t, checkBranch(&Opts{RootDir: cwd}))
+	assert.NoError(t, checkBranch(&Opts{RootDir: cwd, Branches: []string{"main"}}))
+	assert.EqualError(
+		t, checkBranch(&Opts{RootDir: cwd, Branches: []string{"next", "release/*"}}),
+		"Branch 'main' is not configured as a release branch. Releases can only be created from next, release/*",
+	)
+
+	runGit(t, cwd, "checkout", "-q", "-b", "release/1.x")
+	assert.NoError(t, checkBranch(&Opts{RootDir: cwd, Branches: []string{"next", "release/*"}}))
+
+	runGit(t, cwd, "checkout", "-q", "--detach")
+	assert.EqualError(
+		t, checkBranch(&Opts{RootDir: cwd, Branches: []string{"main"}}),
+		"HEAD is not on a branch. Releases can only be created from main",
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestCheckDetached(t *testing.T) {
+	cwd := createGitRepo(t)
+	assert.NoError(t, checkDetached(&Opts{RootDir: cwd}))
+
+	runGit(t, cwd, "checkout", "-q", "--detach")
+	assert.EqualError(t, checkDetached(&Opts{RootDir: cwd}), "HEAD is detached. Check out the branch to release from")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestCheckUpstream(t *testing.T) {
+	cwd := createGitRepo(t)
+
+	// NOTE(joel): No upstream configured.
+	assert.NoError(t, checkUpstream(&Opts{RootDir: cwd}))
+
+	// NOTE(joel): The remote is never contacted, only its local
+	// remote-tracking branch is compared.
+	runGit(t, cwd, "remote", "add", "origin", "/does/not/exist")
+	runGit(t, cwd, "config", "branch.main.remote", "origin")
+	runGit(t, cwd, "config", "branch.main.merge", "refs/heads/main")
+	assert.NoError(t, checkUpstream(&Opts{RootDir: cwd}))
+
+	runGit(t, cwd, "update-ref", "refs/remotes/origin/main", "HEAD")
+	assert.NoError(t, checkUpstream(&Opts{RootDir: cwd}))
+
+	// NOTE(joel): Being ahead is fine.
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: commit #2")
+	assert.NoError(t, checkUpstream(&Opts{RootDir: cwd}))
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: commit #3")
+	runGit(t, cwd, "update-ref", "refs/remotes/origin/main", "HEAD")
+	runGit(t, cwd, "reset", "-q", "--hard", "HEAD~2")
+	assert.EqualError(
+		t, checkUpstream(&Opts{RootDir: cwd}),
+		"Branch 'main' is 2 commit(s) behind 'origin/main'. Pull the changes first",
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestCheckTag(t *testing.T) {
+	cwd := createGitRepo(t)
+	runGit(t, cwd, "tag", "v1.0.0")
+
+	assert.NoError(t, checkTag(&Opts{RootDir: cwd, Tag: "v1.1.0"}))
+	assert.EqualError(t, checkTag(&Opts{RootDir: cwd, Tag: "v1.0.0"}), "Tag 'v1.0.0' already exists")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestCheckReleased(t *testing.T) {
+	cwd := createGitRepo(t)
+	runGit(t, cwd, "tag", "not-a-version")
+	assert.NoError(t, checkReleased(&Opts{RootDir: cwd, Tag: "v1.0.0"}))
+
+	// NOTE(joel): A pre-release can be graduated without new commits, but not
+	// released again.
+	runGit(t, cwd, "tag", "v1.0.0-rc.1")
+	assert.NoError(t, checkReleased(&Opts{RootDir: cwd, Tag: "v1.0.0"}))
+	assert.EqualError(
+		t, checkReleased(&Opts{RootDir: cwd, Tag: "v1.0.0-rc.2"}),
+		"HEAD is already released as 'v1.0.0-rc.1'",
+	)
+
+	runGit(t, cwd, "tag", "v1.0.0")
+	assert.EqualError(
+		t, checkReleased(&Opts{RootDir: cwd, Tag: "v1.1.0"}),
+		"HEAD is already released as 'v1.0.0'",
+	)
+}
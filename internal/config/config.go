@@ -0,0 +1,506 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/joelvoss/release-lit/internal/changelog"
+	"github.com/joelvoss/release-lit/internal/check"
+	"github.com/joelvoss/release-lit/internal/forge"
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NOTE(joel): File names that are looked up (in this order) in the root of the
+// git repository. JSON is a subset of YAML, so all of them are parsed with the
+// same YAML decoder.
+var FileNames = []string{
+	".release-lit.yaml",
+	".release-lit.yml",
+	".release-lit.json",
+}
+
+var projectTypes = []string{"node", "python", "go"}
+
+var prereleaseRegexp *regexp.Regexp
+
+func init() {
+	prereleaseRegexp = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+}
+
+type Config struct {
+	// NOTE(joel): Project type (node, python, go).
+	Type      string          `yaml:"type"`
+	Changelog ChangelogConfig `yaml:"changelog"`
+	Release   ReleaseConfig   `yaml:"release"`
+	Forge     ForgeConfig     `yaml:"forge"`
+	// NOTE(joel): Maps commit types to release types (major, minor, patch,
+	// none), e.g. `perf: patch`. Extends and overrides the default rules.
+	ReleaseRules map[string]string `yaml:"releaseRules"`
+	// NOTE(joel): Version range the next version must satisfy, e.g.
+	// `>=2.0.0 <3.0.0`.
+	VersionRange string `yaml:"versionRange"`
+	// NOTE(joel): Highest allowed version, e.g. `2.x`.
+	MaxVersion string `yaml:"maxVersion"`
+	// NOTE(joel): Release branches and their channels.
+	Branches []BranchConfig `yaml:"branches"`
+	Checks   ChecksConfig   `yaml:"checks"`
+}
+
+type ChecksConfig struct {
+	// NOTE(joel): Names of the pre-flight checks to skip.
+	Skip []string `yaml:"skip"`
+}
+
+type BranchConfig struct {
+	// NOTE(joel): Name of the branch. May be a glob pattern like `release/*`.
+	Name string `yaml:"name"`
+	// NOTE(joel): Pre-release identifier of the channel, e.g. `next`.
+	Prerelease string `yaml:"prerelease"`
+	// NOTE(joel): Allowed version range, e.g. `1.x` or `>=1.2.0 <2.0.0`.
+	Range string `yaml:"range"`
+}
+
+type ChangelogConfig struct {
+	// NOTE(joel): Path of the changelog file, relative to the git root.
+	Path string `yaml:"path"`
+	// NOTE(joel): Changelog format (default, keepachangelog).
+	Format string `yaml:"format"`
+	// NOTE(joel): Path of a custom `text/template` file, relative to the git
+	// root.
+	Template string `yaml:"template"`
+	// NOTE(joel): Path of the `text/template` file of the standalone release
+	// notes, relative to the git root.
+	ReleaseNotesTemplate string `yaml:"releaseNotesTemplate"`
+	// NOTE(joel): Issue URL with `%s` as placeholder for the issue number.
+	IssueURL string `yaml:"issueUrl"`
+	// NOTE(joel): Commit URL with `%s` as placeholder for the sha.
+	CommitURL string `yaml:"commitUrl"`
+	// NOTE(joel): Compare URL with `%s` placeholders for the previous and the
+	// new tag.
+	CompareURL string `yaml:"compareUrl"`
+	// NOTE(joel): Add a "Contributors" section to the changelog.
+	Contributors bool `yaml:"contributors"`
+}
+
+type ForgeConfig struct {
+	// NOTE(joel): Forge type (github, gitlab, gitea, forgejo, bitbucket).
+	// Detected from the `origin` remote if empty.
+	Type string `yaml:"type"`
+	// NOTE(joel): Web URL of the repository. Derived from the `origin` remote
+	// if empty.
+	URL string `yaml:"url"`
+	// NOTE(joel): Base URL of the REST API used to publish releases. Derived
+	// from the repository URL if empty.
+	APIURL string `yaml:"apiUrl"`
+}
+
+type ReleaseConfig struct {
+	// NOTE(joel): Message of the release commit. Must contain a single `%s`
+	// that is replaced with the new version.
+	Message string `yaml:"message"`
+	Author  string `yaml:"author"`
+	Email   string `yaml:"email"`
+	// NOTE(joel): Sign the release commit + tag.
+	Sign bool `yaml:"sign"`
+	// NOTE(joel): Signature format (openpgp, ssh). Taken from the git config
+	// if empty.
+	SignFormat string `yaml:"signFormat"`
+	// NOTE(joel): GPG key id or SSH key (path or `key::` literal). Taken from
+	// the git config if empty.
+	SigningKey string `yaml:"signingKey"`
+}
+
+// ValidationError points to the config key that failed validation.
+type ValidationError struct {
+	File   string
+	Key    string
+	Line   int
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf(
+			"Invalid config file '%s'. Key '%s' (line %d): %s",
+			e.File, e.Key, e.Line, e.Reason,
+		)
+	}
+	return fmt.Sprintf(
+		"Invalid config file '%s'. Key '%s': %s", e.File, e.Key, e.Reason,
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Find looks up the config file in the given root directory. If no config file
+// exists, an empty string is returned.
+func Find(root string) string {
+	for _, name := range FileNames {
+		f := path.Join(root, name)
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return ""
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Load reads and validates the config file at the given path. An empty path
+// results in an empty config.
+func Load(filepath string) (*Config, error) {
+	c := &Config{}
+	if filepath == "" {
+		return c, nil
+	}
+
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading config file. Reason: '%s'", err)
+	}
+
+	if err := Parse(content, c); err != nil {
+		var vErr *ValidationError
+		if errors.As(err, &vErr) {
+			vErr.File = filepath
+			return nil, vErr
+		}
+		return nil, fmt.Errorf(
+			"Invalid config file '%s'. Reason: '%s'", filepath, err,
+		)
+	}
+
+	return c, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Parse decodes the YAML (or JSON) content into c and validates it.
+func Parse(content []byte, c *Config) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return err
+	}
+	// NOTE(joel): An empty file results in an empty document.
+	if len(root.Content) == 0 {
+		return nil
+	}
+
+	doc := root.Content[0]
+	if err := checkKeys(doc, reflect.TypeOf(c).Elem(), ""); err != nil {
+		return err
+	}
+	if err := decodeFields(doc, reflect.ValueOf(c).Elem(), ""); err != nil {
+		return err
+	}
+
+	return c.validate(doc)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// validate checks the values of the config. The document node is used to
+// report the line of the offending key.
+func (c *Config) validate(doc *yaml.Node) error {
+	invalid := func(key, reason string) error {
+		return &ValidationError{Key: key, Line: lineOf(doc, key), Reason: reason}
+	}
+
+	if c.Type != "" && !slices.Contains(projectTypes, c.Type) {
+		return invalid("type", fmt.Sprintf(
+			"unsupported project type '%s', must be one of %s",
+			c.Type, strings.Join(projectTypes, ", "),
+		))
+	}
+	if c.Release.Message != "" {
+		verbs := formatVerbs(c.Release.Message)
+		for _, verb := range verbs {
+			if verb != "%s" {
+				return invalid("release.message", fmt.Sprintf(
+					"unsupported placeholder '%s'. Write a literal '%%' as '%%%%'", verb,
+				))
+			}
+		}
+		if len(verbs) != 1 {
+			return invalid(
+				"release.message",
+				"must contain exactly one '%s' placeholder for the version",
+			)
+		}
+	}
+	if c.Release.Email != "" && !strings.Contains(c.Release.Email, "@") {
+		return invalid("release.email", "must be a valid email address")
+	}
+	if c.Release.SignFormat != "" && !slices.Contains(git.SignFormats, c.Release.SignFormat) {
+		return invalid("release.signFormat", fmt.Sprintf(
+			"unsupported signature format '%s', must be one of %s",
+			c.Release.SignFormat, strings.Join(git.SignFormats, ", "),
+		))
+	}
+	if c.Changelog.IssueURL != "" && strings.Count(c.Changelog.IssueURL, "%s") != 1 {
+		return invalid(
+			"changelog.issueUrl",
+			"must contain exactly one '%s' placeholder for the issue number",
+		)
+	}
+	if c.Changelog.Format != "" && !slices.Contains(changelog.Formats, c.Changelog.Format) {
+		return invalid("changelog.format", fmt.Sprintf(
+			"unsupported changelog format '%s', must be one of %s",
+			c.Changelog.Format, strings.Join(changelog.Formats, ", "),
+		))
+	}
+	if c.Changelog.CommitURL != "" && strings.Count(c.Changelog.CommitURL, "%s") != 1 {
+		return invalid(
+			"changelog.commitUrl",
+			"must contain exactly one '%s' placeholder for the commit sha",
+		)
+	}
+	if c.Changelog.CompareURL != "" && strings.Count(c.Changelog.CompareURL, "%s") != 2 {
+		return invalid(
+			"changelog.compareUrl",
+			"must contain exactly two '%s' placeholders for the previous and the new tag",
+		)
+	}
+	if c.Forge.Type != "" && !slices.Contains(forge.Types, c.Forge.Type) {
+		return invalid("forge.type", fmt.Sprintf(
+			"unsupported forge '%s', must be one of %s",
+			c.Forge.Type, strings.Join(forge.Types, ", "),
+		))
+	}
+	if c.Forge.URL != "" {
+		if _, err := forge.New(c.Forge.Type, c.Forge.URL); err != nil {
+			return invalid("forge.url", err.Error())
+		}
+	}
+	if c.Forge.APIURL != "" {
+		u, err := url.Parse(c.Forge.APIURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalid("forge.apiUrl", fmt.Sprintf("invalid URL '%s'", c.Forge.APIURL))
+		}
+	}
+	if c.VersionRange != "" {
+		if _, err := semver.ParseConstraint(c.VersionRange); err != nil {
+			return invalid("versionRange", err.Error())
+		}
+	}
+	if c.MaxVersion != "" {
+		if _, err := semver.ParseConstraint("<=" + c.MaxVersion); err != nil {
+			return invalid("maxVersion", err.Error())
+		}
+	}
+	for i, b := range c.Branches {
+		key := fmt.Sprintf("branches[%d]", i)
+		if b.Name == "" {
+			return invalid(key+".name", "must not be empty")
+		}
+		if _, err := path.Match(b.Name, ""); err != nil {
+			return invalid(key+".name", fmt.Sprintf("invalid branch pattern '%s'", b.Name))
+		}
+		if b.Prerelease != "" && !prereleaseRegexp.MatchString(b.Prerelease) {
+			return invalid(key+".prerelease", fmt.Sprintf(
+				"invalid pre-release identifier '%s'", b.Prerelease,
+			))
+		}
+		if b.Range != "" {
+			if _, err := semver.ParseConstraint(b.Range); err != nil {
+				return invalid(key+".range", err.Error())
+			}
+		}
+	}
+	for i, name := range c.Checks.Skip {
+		if !slices.Contains(check.Names, name) {
+			return invalid(fmt.Sprintf("checks.skip[%d]", i), fmt.Sprintf(
+				"unknown check '%s', must be one of %s",
+				name, strings.Join(check.Names, ", "),
+			))
+		}
+	}
+	// NOTE(joel): Iterate in sorted order so that the reported key is stable.
+	for _, t := range slices.Sorted(maps.Keys(c.ReleaseRules)) {
+		if _, err := semver.ParseReleaseType(c.ReleaseRules[t]); err != nil {
+			return invalid("releaseRules."+t, err.Error())
+		}
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// formatVerbs returns the verbs (e.g. `%s`) of the fmt format string. Escaped
+// percent signs (`%%`) are skipped and a trailing `%` is returned as is.
+func formatVerbs(format string) []string {
+	verbs := make([]string, 0)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 == len(format) {
+			verbs = append(verbs, "%")
+			break
+		}
+		_, size := utf8.DecodeRuneInString(format[i+1:])
+		if format[i+1] != '%' {
+			verbs = append(verbs, format[i:i+1+size])
+		}
+		i += size
+	}
+	return verbs
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkKeys walks the mapping node and reports the first key that has no
+// corresponding field in the struct type t.
+func checkKeys(node *yaml.Node, t reflect.Type, prefix string) error {
+	// NOTE(joel): Empty values (e.g. `changelog:`) are allowed for any key.
+	if node.Tag == "!!null" {
+		return nil
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct {
+		if node.Kind != yaml.SequenceNode {
+			return &ValidationError{Key: prefix, Line: node.Line, Reason: "must be a list"}
+		}
+		for i, item := range node.Content {
+			key := fmt.Sprintf("%s[%d]", prefix, i)
+			if err := checkKeys(item, t.Elem(), key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return &ValidationError{
+			Key:    keyOrRoot(prefix),
+			Line:   node.Line,
+			Reason: "must be a mapping",
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := joinKey(prefix, keyNode.Value)
+
+		field, ok := fieldByTag(t, keyNode.Value)
+		if !ok {
+			return &ValidationError{Key: key, Line: keyNode.Line, Reason: "unknown key"}
+		}
+		if err := checkKeys(valueNode, field.Type, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// decodeFields decodes every key of the mapping node into the matching field
+// of v so that type errors can be reported with the offending key.
+func decodeFields(node *yaml.Node, v reflect.Value, prefix string) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := joinKey(prefix, keyNode.Value)
+
+		field, _ := fieldByTag(v.Type(), keyNode.Value)
+		fv := v.FieldByIndex(field.Index)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := decodeFields(valueNode, fv, key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := valueNode.Decode(fv.Addr().Interface()); err != nil {
+			return &ValidationError{
+				Key:    key,
+				Line:   valueNode.Line,
+				Reason: fmt.Sprintf("invalid value '%s'", valueNode.Value),
+			}
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// fieldByTag returns the struct field with the given yaml tag name.
+func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if tag == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// lineOf returns the line of the given key in the document. If the key can't
+// be found, the line of its closest parent (or 0) is returned. Keys are dotted
+// paths with optional list indices, e.g. `branches[1].name`.
+func lineOf(doc *yaml.Node, key string) int {
+	node := doc
+	line := 0
+	for _, part := range strings.Split(key, ".") {
+		name, index, hasIndex := strings.Cut(strings.TrimSuffix(part, "]"), "[")
+		if node == nil || node.Kind != yaml.MappingNode {
+			return line
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == name {
+				line = node.Content[i].Line
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return line
+		}
+		if hasIndex {
+			i, err := strconv.Atoi(index)
+			if err != nil || next.Kind != yaml.SequenceNode || i >= len(next.Content) {
+				return line
+			}
+			next = next.Content[i]
+			line = next.Line
+		}
+		node = next
+	}
+	return line
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func keyOrRoot(key string) string {
+	if key == "" {
+		return "<root>"
+	}
+	return key
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Config
+		error    string
+	}{
+		{
+			name:     "Empty file",
+			content:  "",
+			expected: Config{},
+		},
+		{
+			name: "YAML",
+			content: `type: python
+changelog:
+  path: ./HISTORY.md
+release:
+  message: "release: %s"
+  author: Release Bot
+  email: bot@example.com
+`,
+			expected: Config{
+				Type:      "python",
+				Changelog: ChangelogConfig{Path: "./HISTORY.md"},
+				Release: ReleaseConfig{
+					Message: "release: %s",
+					Author:  "Release Bot",
+					Email:   "bot@example.com",
+				},
+			},
+		},
+		{
+			name:     "JSON",
+			content:  `{"type": "go", "changelog": {"path": "CHANGES.md"}}`,
+			expected: Config{Type: "go", Changelog: ChangelogConfig{Path: "CHANGES.md"}},
+		},
+		{
+			name:     "Empty mapping",
+			content:  "changelog:\n",
+			expected: Config{},
+		},
+		{
+			name:    "Changelog template",
+			content: "changelog:\n  template: ./changelog.tpl\n  releaseNotesTemplate: ./notes.tpl\n  issueUrl: https://example.com/issues/%s\n",
+			expected: Config{
+				Changelog: ChangelogConfig{
+					Template:             "./changelog.tpl",
+					ReleaseNotesTemplate: "./notes.tpl",
+					IssueURL:             "https://example.com/issues/%s",
+				},
+			},
+		},
+		{
+			name:     "Contributors",
+			content:  "changelog:\n  contributors: true\n",
+			expected: Config{Changelog: ChangelogConfig{Contributors: true}},
+		},
+		{
+			name:    "Invalid contributors",
+			content: "changelog:\n  contributors: maybe\n",
+			error:   "Invalid config file ''. Key 'changelog.contributors' (line 2): invalid value 'maybe'",
+		},
+		{
+			name:    "Forge",
+			content: "forge:\n  type: gitea\n  url: https://git.example.com/owner/repo\n  apiUrl: https://git.example.com/api/v1\nchangelog:\n  commitUrl: https://git.example.com/c/%s\n  compareUrl: https://git.example.com/%s..%s\n",
+			expected: Config{
+				Forge: ForgeConfig{
+					Type:   "gitea",
+					URL:    "https://git.example.com/owner/repo",
+					APIURL: "https://git.example.com/api/v1",
+				},
+				Changelog: ChangelogConfig{
+					CommitURL:  "https://git.example.com/c/%s",
+					CompareURL: "https://git.example.com/%s..%s",
+				},
+			},
+		},
+		{
+			name:    "Unsupported forge",
+			content: "forge:\n  type: sourcehut\n",
+			error:   "Invalid config file ''. Key 'forge.type' (line 2): unsupported forge 'sourcehut', must be one of github, gitlab, gitea, forgejo, bitbucket",
+		},
+		{
+			name:    "Forge URL of unknown host without type",
+			content: "forge:\n  url: https://git.example.com/owner/repo\n",
+			error:   "Invalid config file ''. Key 'forge.url' (line 2): unsupported forge '', must be one of github, gitlab, gitea, forgejo, bitbucket",
+		},
+		{
+			name:    "Invalid forge API URL",
+			content: "forge:\n  apiUrl: git.example.com/api\n",
+			error:   "Invalid config file ''. Key 'forge.apiUrl' (line 2): invalid URL 'git.example.com/api'",
+		},
+		{
+			name:    "Compare URL with one placeholder",
+			content: "changelog:\n  compareUrl: https://example.com/compare/%s\n",
+			error:   "Invalid config file ''. Key 'changelog.compareUrl' (line 2): must contain exactly two '%s' placeholders for the previous and the new tag",
+		},
+		{
+			name:     "Changelog format",
+			content:  "changelog:\n  format: keepachangelog\n",
+			expected: Config{Changelog: ChangelogConfig{Format: "keepachangelog"}},
+		},
+		{
+			name:    "Unsupported changelog format",
+			content: "changelog:\n  format: markdown\n",
+			error:   "Invalid config file ''. Key 'changelog.format' (line 2): unsupported changelog format 'markdown', must be one of default, keepachangelog",
+		},
+		{
+			name:    "Issue URL without placeholder",
+			content: "changelog:\n  issueUrl: https://example.com/issues\n",
+			error:   "Invalid config file ''. Key 'changelog.issueUrl' (line 2): must contain exactly one '%s' placeholder for the issue number",
+		},
+		{
+			name:    "Release rules",
+			content: "releaseRules:\n  perf: patch\n  deps: none\n",
+			expected: Config{
+				ReleaseRules: map[string]string{"perf": "patch", "deps": "none"},
+			},
+		},
+		{
+			name:    "Invalid release rule",
+			content: "releaseRules:\n  perf: patch\n  revert: tiny\n",
+			error:   "Invalid config file ''. Key 'releaseRules.revert' (line 3): invalid release type 'tiny', must be one of none, patch, minor, major",
+		},
+		{
+			name: "Branches",
+			content: `branches:
+  - name: main
+  - name: next
+    prerelease: next
+  - name: "1.x"
+  - name: release/*
+    range: 2.1.x
+`,
+			expected: Config{
+				Branches: []BranchConfig{
+					{Name: "main"},
+					{Name: "next", Prerelease: "next"},
+					{Name: "1.x"},
+					{Name: "release/*", Range: "2.1.x"},
+				},
+			},
+		},
+		{
+			name:    "Branches not a list",
+			content: "branches: main\n",
+			error:   "Invalid config file ''. Key 'branches' (line 1): must be a list",
+		},
+		{
+			name:    "Unknown branch key",
+			content: "branches:\n  - name: main\n  - name: next\n    channel: next\n",
+			error:   "Invalid config file ''. Key 'branches[1].channel' (line 4): unknown key",
+		},
+		{
+			name:    "Branch without name",
+			content: "branches:\n  - name: main\n  - prerelease: next\n",
+			error:   "Invalid config file ''. Key 'branches[1].name' (line 3): must not be empty",
+		},
+		{
+			name:    "Invalid branch range",
+			content: "branches:\n  - name: main\n    range: '>=1.y'\n",
+			error:   "Invalid config file ''. Key 'branches[0].range' (line 3): invalid constraint '>=1.y': invalid comparator '>=1.y'",
+		},
+		{
+			name:    "Signing",
+			content: "release:\n  sign: true\n  signFormat: ssh\n  signingKey: ~/.ssh/id_ed25519\n",
+			expected: Config{
+				Release: ReleaseConfig{Sign: true, SignFormat: "ssh", SigningKey: "~/.ssh/id_ed25519"},
+			},
+		},
+		{
+			name:    "Invalid signature format",
+			content: "release:\n  sign: true\n  signFormat: x509\n",
+			error:   "Invalid config file ''. Key 'release.signFormat' (line 3): unsupported signature format 'x509', must be one of openpgp, ssh",
+		},
+		{
+			name:    "Checks",
+			content: "checks:\n  skip: [upstream, released]\n",
+			expected: Config{
+				Checks: ChecksConfig{
+					Skip: []string{"upstream", "released"},
+				},
+			},
+		},
+		{
+			name:    "Unknown check",
+			content: "checks:\n  skip:\n    - upstream\n    - dirty\n",
+			error:   "Invalid config file ''. Key 'checks.skip[1]' (line 4): unknown check 'dirty', must be one of clean, branch, detached, upstream, tag, released",
+		},
+		{
+			name:    "Invalid branch pattern",
+			content: "branches:\n  - name: 'release/['\n",
+			error:   "Invalid config file ''. Key 'branches[0].name' (line 2): invalid branch pattern 'release/['",
+		},
+		{
+			name:     "Version range",
+			content:  "versionRange: '>=2.0.0 <3.0.0'\nmaxVersion: 2.x\n",
+			expected: Config{VersionRange: ">=2.0.0 <3.0.0", MaxVersion: "2.x"},
+		},
+		{
+			name:    "Invalid version range",
+			content: "versionRange: '>=2.0.0 <3.0.0'\nmaxVersion: two\n",
+			error:   "Invalid config file ''. Key 'maxVersion' (line 2): invalid constraint '<=two': invalid comparator '<=two'",
+		},
+		{
+			name:    "Unknown key",
+			content: "type: node\nchangelog:\n  pth: ./HISTORY.md\n",
+			error:   "Invalid config file ''. Key 'changelog.pth' (line 3): unknown key",
+		},
+		{
+			name:    "Invalid type",
+			content: "type: rust\n",
+			error:   "Invalid config file ''. Key 'type' (line 1): unsupported project type 'rust', must be one of node, python, go",
+		},
+		{
+			name:    "Invalid value",
+			content: "release:\n  author: [a, b]\n",
+			error:   "Invalid config file ''. Key 'release.author' (line 2): invalid value ''",
+		},
+		{
+			name:    "Scalar instead of mapping",
+			content: "release: bot\n",
+			error:   "Invalid config file ''. Key 'release' (line 1): must be a mapping",
+		},
+		{
+			name:    "Message without placeholder",
+			content: "release:\n  message: release\n",
+			error:   "Invalid config file ''. Key 'release.message' (line 2): must contain exactly one '%s' placeholder for the version",
+		},
+		{
+			name:    "Message with another placeholder",
+			content: "release:\n  message: \"release: v%s (100% done)\"\n",
+			error:   "Invalid config file ''. Key 'release.message' (line 2): unsupported placeholder '% '. Write a literal '%' as '%%'",
+		},
+		{
+			name:     "Message with escaped percent sign",
+			content:  "release:\n  message: \"release: v%s (100%% done)\"\n",
+			expected: Config{Release: ReleaseConfig{Message: "release: v%s (100%% done)"}},
+		},
+		{
+			name:    "Invalid email",
+			content: "release:\n  email: bot\n",
+			error:   "Invalid config file ''. Key 'release.email' (line 2): must be a valid email address",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := Config{}
+			err := Parse([]byte(test.content), &c)
+			if test.error == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, c)
+			} else {
+				assert.EqualError(t, err, test.error)
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestFindAndLoad(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "release-lit-config-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	assert.Equal(t, "", Find(tempDir))
+	c, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, c)
+
+	f := path.Join(tempDir, ".release-lit.yml")
+	require.NoError(t, os.WriteFile(f, []byte("type: go\n"), 0644))
+	assert.Equal(t, f, Find(tempDir))
+
+	c, err = Load(f)
+	require.NoError(t, err)
+	assert.Equal(t, "go", c.Type)
+
+	require.NoError(t, os.WriteFile(f, []byte("type: rust\n"), 0644))
+	_, err = Load(f)
+	assert.EqualError(t, err, "Invalid config file '"+f+"'. Key 'type' (line 1): unsupported project type 'rust', must be one of node, python, go")
+}
@@ -0,0 +1,220 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// NOTE(joel): Number of unchanged lines shown around each change.
+const contextLines = 3
+
+// NOTE(joel): Maximum number of cells of the LCS table (~32 MB). Larger
+// changes are shown as a replacement of all changed lines.
+var maxTableSize = 1 << 22
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	text string
+	// NOTE(joel): 0-based line indices into the old (a) and new (b) content
+	// at the position of this operation.
+	a, b int
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Unified returns a unified diff between the old content a and the new content
+// b. The names are used in the `---` and `+++` header lines. If both contents
+// are equal, an empty string is returned.
+func Unified(fromName, toName string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+
+	ops := editScript(splitLines(a), splitLines(b))
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromName, toName)
+
+	for _, h := range hunks(ops) {
+		first := ops[h[0]]
+
+		aStart, aCount := first.a, 0
+		bStart, bCount := first.b, 0
+		for _, o := range ops[h[0]:h[1]] {
+			if o.kind != opInsert {
+				aCount++
+			}
+			if o.kind != opDelete {
+				bCount++
+			}
+		}
+
+		fmt.Fprintf(
+			&buf, "@@ -%s +%s @@\n",
+			hunkRange(aStart, aCount), hunkRange(bStart, bCount),
+		)
+		for _, o := range ops[h[0]:h[1]] {
+			// NOTE(joel): Only the last line of a file can lack its newline. It is
+			// marked like `diff -u` does, so that the diff can be applied.
+			text, ok := strings.CutSuffix(o.text, "\n")
+			fmt.Fprintf(&buf, "%c%s\n", o.kind, text)
+			if !ok {
+				buf.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return buf.String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// splitLines splits content into lines with their trailing newline. A last
+// line without a newline differs from the same line with one, e.g. if a
+// newline is added at the end of the file.
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// editScript computes the shortest sequence of line operations that turns a
+// into b. Common prefixes and suffixes are stripped before running the LCS
+// algorithm to keep the table small for typical changes like prepending a
+// section to a changelog. If the table would still exceed maxTableSize, the
+// remaining lines of a are deleted and the ones of b inserted instead.
+func editScript(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{kind: opEqual, text: a[i], a: i, b: i})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(ma)+1)*(len(mb)+1) > maxTableSize {
+		for i, line := range ma {
+			ops = append(ops, op{kind: opDelete, text: line, a: prefix + i, b: prefix})
+		}
+		for j, line := range mb {
+			ops = append(ops, op{kind: opInsert, text: line, a: prefix + len(ma), b: prefix + j})
+		}
+		return appendSuffix(ops, a, b, suffix)
+	}
+
+	// NOTE(joel): lcs[i][j] holds the length of the longest common subsequence
+	// of ma[i:] and mb[j:].
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			ops = append(ops, op{kind: opEqual, text: ma[i], a: prefix + i, b: prefix + j})
+			i++
+			j++
+		case i < len(ma) && (j == len(mb) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{kind: opDelete, text: ma[i], a: prefix + i, b: prefix + j})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, text: mb[j], a: prefix + i, b: prefix + j})
+			j++
+		}
+	}
+
+	return appendSuffix(ops, a, b, suffix)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// appendSuffix appends the common suffix of a and b with the given length to
+// the operations.
+func appendSuffix(ops []op, a, b []string, suffix int) []op {
+	for k := 0; k < suffix; k++ {
+		ia, ib := len(a)-suffix+k, len(b)-suffix+k
+		ops = append(ops, op{kind: opEqual, text: a[ia], a: ia, b: ib})
+	}
+	return ops
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// hunks groups the operations into hunks of changes surrounded by context
+// lines. Each hunk is returned as a half-open [start, end) range into ops.
+func hunks(ops []op) [][2]int {
+	var result [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+
+		start := max(i-contextLines, 0)
+		end := i
+		// NOTE(joel): Extend the hunk as long as the next change is close enough
+		// that their context lines would overlap.
+		for k := i; k < len(ops); k++ {
+			if ops[k].kind != opEqual {
+				end = k
+				continue
+			}
+			if k-end > 2*contextLines {
+				break
+			}
+		}
+		end = min(end+contextLines+1, len(ops))
+
+		result = append(result, [2]int{start, end})
+		i = end - 1
+	}
+	return result
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// hunkRange formats a hunk range as used in the `@@` hunk header.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// NOTE(joel): Empty ranges reference the line before the change.
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
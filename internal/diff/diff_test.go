@@ -0,0 +1,155 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{
+			name:     "Equal content",
+			a:        "a\nb\n",
+			b:        "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "New file",
+			a:    "",
+			b:    "a\nb\n",
+			expected: `--- a/file
++++ b/file
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name: "Changed line",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: `--- a/file
++++ b/file
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name: "Prepended lines",
+			a:    "# Changelog\n\n## 0.1.0\n",
+			b:    "# Changelog\n\n## 1.0.0\n\n## 0.1.0\n",
+			expected: `--- a/file
++++ b/file
+@@ -1,3 +1,5 @@
+ # Changelog
+ 
++## 1.0.0
++
+ ## 0.1.0
+`,
+		},
+		{
+			name: "Separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: `--- a/file
++++ b/file
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++twelve
+`,
+		},
+		{
+			name: "No newline at end of file",
+			a:    "1\n2",
+			b:    "1\n2\n3",
+			expected: `--- a/file
++++ b/file
+@@ -1,2 +1,3 @@
+ 1
+-2
+\ No newline at end of file
++2
++3
+\ No newline at end of file
+`,
+		},
+		{
+			name: "Newline added at end of file",
+			a:    "1\n2",
+			b:    "1\n2\n",
+			expected: `--- a/file
++++ b/file
+@@ -1,2 +1,2 @@
+ 1
+-2
+\ No newline at end of file
++2
+`,
+		},
+		{
+			name: "Unchanged last line without newline",
+			a:    "1\n2\n3\n4\n5",
+			b:    "one\n2\n3\n4\n5",
+			expected: `--- a/file
++++ b/file
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Unified("a/file", "b/file", []byte(test.a), []byte(test.b))
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestUnifiedLargeChange(t *testing.T) {
+	// NOTE(joel): Changes exceeding the table size are shown as a replacement.
+	defer func(size int) { maxTableSize = size }(maxTableSize)
+	maxTableSize = 4
+
+	assert.Equal(t, `--- a/file
++++ b/file
+@@ -1,4 +1,4 @@
+ 1
+-a
+-b
++b
++c
+ 2
+`, Unified("a/file", "b/file", []byte("1\na\nb\n2\n"), []byte("1\nb\nc\n2\n")))
+}
@@ -0,0 +1,220 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Gitea     = "gitea"
+	Forgejo   = "forgejo"
+	Bitbucket = "bitbucket"
+)
+
+// Types are the supported forge types.
+var Types = []string{GitHub, GitLab, Gitea, Forgejo, Bitbucket}
+
+var scpRegexp *regexp.Regexp
+
+func init() {
+	// NOTE(joel): scp-like syntax, e.g. `git@github.com:owner/repo.git`.
+	scpRegexp = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+}
+
+// Forge is the hosting platform of a repository.
+type Forge struct {
+	// NOTE(joel): One of Types.
+	Type string
+	// NOTE(joel): Web URL of the repository without trailing slash, e.g.
+	// `https://github.com/owner/repo`.
+	URL string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// New returns a forge of the given type and repository URL. If typ is empty,
+// the type is guessed from the host of the URL.
+func New(typ string, repoURL string) (*Forge, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid repository URL '%s'", repoURL)
+	}
+	if typ == "" {
+		typ = guessType(u.Hostname())
+	}
+	if !slices.Contains(Types, typ) {
+		return nil, fmt.Errorf(
+			"unsupported forge '%s', must be one of %s", typ, strings.Join(Types, ", "),
+		)
+	}
+	return &Forge{Type: typ, URL: strings.TrimSuffix(repoURL, "/")}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Detect returns the forge of the given git remote URL. If typ is empty, the
+// type is guessed from the host. If the remote can't be parsed or the forge
+// is unknown, nil is returned.
+func Detect(typ string, remote string) *Forge {
+	repoURL := RepoURL(remote)
+	if repoURL == "" {
+		return nil
+	}
+	f, err := New(typ, repoURL)
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// RepoURL converts a git remote URL (HTTP(S), SSH or scp-like) into the web
+// URL of the repository. If the remote can't be parsed, an empty string is
+// returned.
+func RepoURL(remote string) string {
+	remote = strings.TrimSpace(remote)
+
+	var scheme, host, repoPath string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Host == "" {
+			return ""
+		}
+		switch u.Scheme {
+		case "http", "https":
+			// NOTE(joel): Keep the port of HTTP remotes, but drop credentials.
+			scheme, host = u.Scheme, u.Host
+		case "ssh", "git", "git+ssh":
+			scheme, host = "https", u.Hostname()
+		default:
+			return ""
+		}
+		repoPath = u.Path
+	} else if m := scpRegexp.FindStringSubmatch(remote); m != nil {
+		scheme, host, repoPath = "https", m[1], m[2]
+	} else {
+		return ""
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if repoPath == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s://%s/%s", scheme, host, repoPath)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// guessType guesses the forge type from the host name. Returns an empty
+// string for unknown hosts.
+func guessType(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case host == "github.com" || strings.Contains(host, "github"):
+		return GitHub
+	case host == "gitlab.com" || strings.Contains(host, "gitlab"):
+		return GitLab
+	case host == "codeberg.org" || strings.Contains(host, "forgejo"):
+		return Forgejo
+	case strings.Contains(host, "gitea"):
+		return Gitea
+	case host == "bitbucket.org" || strings.Contains(host, "bitbucket"):
+		return Bitbucket
+	}
+	return ""
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// CommitURL returns the URL of a commit with `%s` as placeholder for the sha.
+func (f *Forge) CommitURL() string {
+	base := escape(f.URL)
+	switch f.Type {
+	case GitLab:
+		return base + "/-/commit/%s"
+	case Bitbucket:
+		return base + "/commits/%s"
+	}
+	return base + "/commit/%s"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// IssueURL returns the URL of an issue with `%s` as placeholder for the issue
+// number.
+func (f *Forge) IssueURL() string {
+	base := escape(f.URL)
+	if f.Type == GitLab {
+		return base + "/-/issues/%s"
+	}
+	return base + "/issues/%s"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// CompareURL returns the URL comparing two tags with two `%s` placeholders for
+// the previous and the new tag (in this order).
+func (f *Forge) CompareURL() string {
+	base := escape(f.URL)
+	switch f.Type {
+	case GitLab:
+		return base + "/-/compare/%s...%s"
+	case Bitbucket:
+		// NOTE(joel): Bitbucket expects the new tag first, separated by a
+		// URL-encoded carriage return.
+		return base + "/branches/compare/%[2]s%%0D%[1]s"
+	}
+	return base + "/compare/%s...%s"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Path returns the path of the repository without leading slash, e.g.
+// `owner/repo` or `group/subgroup/repo`.
+func (f *Forge) Path() string {
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(u.Path, "/")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// APIURL returns the base URL of the REST API of the forge without trailing
+// slash. Self-hosted GitHub Enterprise instances serve the API below
+// `/api/v3`.
+func (f *Forge) APIURL() string {
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		return ""
+	}
+	base := u.Scheme + "://" + u.Host
+	switch f.Type {
+	case GitHub:
+		if strings.EqualFold(u.Hostname(), "github.com") {
+			return "https://api.github.com"
+		}
+		return base + "/api/v3"
+	case GitLab:
+		return base + "/api/v4"
+	case Gitea, Forgejo:
+		return base + "/api/v1"
+	case Bitbucket:
+		return "https://api.bitbucket.org/2.0"
+	}
+	return ""
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// escape escapes `%` so that the URL can be used as format string.
+func escape(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
@@ -17,10 +17,11 @@ const (
 	CommitTypeMisc
 )
 
-var commitRegexp *regexp.Regexp
+var commitRegexp, personRegexp *regexp.Regexp
 
 func init() {
 	commitRegexp = regexp.MustCompile(`(?ms)^(?<type>\w*)(?:\((?<scope>[\w$.\-*/ ]*)\))?(?<breaking>\!)?:(?<message>.*)`)
+	personRegexp = regexp.MustCompile(`^(.*?)\s*<([^<>]+)>$`)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -30,6 +31,7 @@ type Sha struct {
 	Short string `json:"short"`
 }
 
+// Committer is a person (author, co-author or committer) of a commit.
 type Committer struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
@@ -37,6 +39,7 @@ type Committer struct {
 
 type Commit struct {
 	Sha       Sha       `json:"sha"`
+	Author    Committer `json:"author"`
 	Committer Committer `json:"committer"`
 	Date      string    `json:"date"`
 	Subject   string    `json:"subject"`
@@ -45,12 +48,18 @@ type Commit struct {
 	Scope     string    `json:"scope,omitempty"`
 	Type      string    `json:"type,omitempty"`
 	Message   string    `json:"message,omitempty"`
+	// NOTE(joel): Trailers (footers) of the body, e.g. `Refs: #123`.
+	Trailers []Trailer `json:"trailers,omitempty"`
+	// NOTE(joel): Description of the `BREAKING CHANGE:` footer (if any).
+	BreakingChange string `json:"breakingChange,omitempty"`
+	// NOTE(joel): People listed in `Co-authored-by:` footers.
+	CoAuthors []Committer `json:"coAuthors,omitempty"`
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
-// Analyze parses the commit subject and sets the type, scope, breaking and
-// subject.
+// PostProcess parses the commit subject and body and sets the type, scope,
+// message, trailers and breaking change.
 func (c *Commit) PostProcess() error {
 	matches := commitRegexp.FindStringSubmatch(c.Subject)
 	if matches == nil {
@@ -59,14 +68,53 @@ func (c *Commit) PostProcess() error {
 
 	c.Type = strings.TrimSpace(matches[1])
 	c.Scope = strings.TrimSpace(matches[2])
-	c.Breaking = matches[3] != "" || strings.Contains(c.Body, "BREAKING CHANGE")
 	c.Message = strings.TrimSpace(matches[4])
 
+	c.Trailers = parseTrailers(c.Body)
+	c.Breaking = matches[3] != ""
+	c.BreakingChange = ""
+	for _, t := range c.Trailers {
+		if t.IsBreaking() {
+			c.Breaking = true
+			c.BreakingChange = t.Value
+			break
+		}
+	}
+
+	c.CoAuthors = nil
+	for _, value := range c.TrailerValues("Co-authored-by") {
+		if person, ok := parsePerson(value); ok {
+			c.CoAuthors = append(c.CoAuthors, person)
+		}
+	}
+
 	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
+// Authors returns the author of the commit followed by its co-authors.
+func (c *Commit) Authors() []Committer {
+	authors := make([]Committer, 0, 1+len(c.CoAuthors))
+	if c.Author.Name != "" || c.Author.Email != "" {
+		authors = append(authors, c.Author)
+	}
+	return append(authors, c.CoAuthors...)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// parsePerson parses a `Name <email>` string.
+func parsePerson(s string) (Committer, bool) {
+	m := personRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Committer{}, false
+	}
+	return Committer{Name: strings.TrimSpace(m[1]), Email: strings.TrimSpace(m[2])}, true
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 // ToString returns the commit as a string.
 func (c *Commit) ToString() string {
 	raw := c.Subject
@@ -78,43 +126,100 @@ func (c *Commit) ToString() string {
 
 ////////////////////////////////////////////////////////////////////////////////
 
-// GroupByType groups commits by type.
-func GroupByType(commits []*Commit) map[CommitType][]*Commit {
+// Rules maps commit types to the release type they trigger. Breaking changes
+// always trigger a major release regardless of their type.
+type Rules map[string]int
+
+// DefaultRules are the rules used if no rules are given: `feat` commits
+// trigger a minor release and `fix` commits trigger a patch release.
+var DefaultRules = Rules{
+	"feat": semver.ReleaseTypeMinor,
+	"fix":  semver.ReleaseTypePatch,
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ReleaseType returns the release type the given commit triggers.
+func (r Rules) ReleaseType(c *Commit) int {
+	if c.Breaking {
+		return semver.ReleaseTypeMajor
+	}
+	if r == nil {
+		r = DefaultRules
+	}
+	return r[c.Type]
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Merge returns a copy of r with the rules of other added. Rules in other take
+// precedence.
+func (r Rules) Merge(other Rules) Rules {
+	merged := make(Rules, len(r)+len(other))
+	for t, release := range r {
+		merged[t] = release
+	}
+	for t, release := range other {
+		merged[t] = release
+	}
+	return merged
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ParseRules parses rules in the form `<type>=<release>`, e.g. `perf=patch`.
+// Valid releases are `major`, `minor`, `patch` and `none`.
+func ParseRules(rules []string) (Rules, error) {
+	parsed := make(Rules, len(rules))
+	for _, rule := range rules {
+		t, release, ok := strings.Cut(rule, "=")
+		t = strings.TrimSpace(t)
+		if !ok || t == "" {
+			return nil, fmt.Errorf("invalid release rule '%s', expected '<type>=<release>'", rule)
+		}
+		releaseType, err := semver.ParseReleaseType(strings.TrimSpace(release))
+		if err != nil {
+			return nil, fmt.Errorf("invalid release rule '%s': %s", rule, err)
+		}
+		parsed[t] = releaseType
+	}
+	return parsed, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GroupByType groups commits by the release type they trigger according to
+// the given rules. If rules is nil, the DefaultRules are used. Only breaking
+// changes are grouped as such, so commits of a type mapped to a major release
+// are listed along with the features.
+func GroupByType(commits []*Commit, rules Rules) map[CommitType][]*Commit {
 	grouped := make(map[CommitType][]*Commit)
 	for _, c := range commits {
 		if c.Breaking {
 			grouped[CommitTypeBreaking] = append(grouped[CommitTypeBreaking], c)
 			continue
 		}
-		if c.Type == "feat" {
+		switch rules.ReleaseType(c) {
+		case semver.ReleaseTypeMajor, semver.ReleaseTypeMinor:
 			grouped[CommitTypeFeat] = append(grouped[CommitTypeFeat], c)
-			continue
-		}
-		if c.Type == "fix" {
+		case semver.ReleaseTypePatch:
 			grouped[CommitTypeFix] = append(grouped[CommitTypeFix], c)
-			continue
+		default:
+			grouped[CommitTypeMisc] = append(grouped[CommitTypeMisc], c)
 		}
-		grouped[CommitTypeMisc] = append(grouped[CommitTypeMisc], c)
 	}
 	return grouped
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
-// GetNextRelease determines the release type based on the commits.
-func GetNextReleaseType(commits []*Commit) int {
+// GetNextRelease determines the release type based on the commits. If rules
+// is nil, the DefaultRules are used.
+func GetNextReleaseType(commits []*Commit, rules Rules) int {
 	releaseType := semver.ReleaseTypeNone
 
 	for _, c := range commits {
-		if c.Breaking {
-			releaseType = semver.ReleaseTypeMajor
-		}
-		if c.Type == "feat" && releaseType <= semver.ReleaseTypeMinor {
-			releaseType = semver.ReleaseTypeMinor
-		}
-		if c.Type == "fix" && releaseType <= semver.ReleaseTypePatch {
-			releaseType = semver.ReleaseTypePatch
-		}
+		releaseType = max(releaseType, rules.ReleaseType(c))
 	}
 
 	return releaseType
@@ -13,10 +13,11 @@ import (
 ////////////////////////////////////////////////////////////////////////////////
 
 type ExpectedCommit struct {
-	Type     string
-	Scope    string
-	Breaking bool
-	Message  string
+	Type           string
+	Scope          string
+	Breaking       bool
+	Message        string
+	BreakingChange string
 }
 
 func TestPostProcess(t *testing.T) {
@@ -59,10 +60,11 @@ func TestPostProcess(t *testing.T) {
 				Body:    "BREAKING CHANGE: this is a breaking change",
 			},
 			expected: ExpectedCommit{
-				Type:     "feat",
-				Scope:    "",
-				Breaking: true,
-				Message:  "add breaking change",
+				Type:           "feat",
+				Scope:          "",
+				Breaking:       true,
+				Message:        "add breaking change",
+				BreakingChange: "this is a breaking change",
 			},
 		},
 		{
@@ -84,13 +86,76 @@ func TestPostProcess(t *testing.T) {
 				Subject: "feat(scope): add breaking change",
 				Body:    "BREAKING CHANGE: this is a breaking change",
 			},
+			expected: ExpectedCommit{
+				Type:           "feat",
+				Scope:          "scope",
+				Breaking:       true,
+				Message:        "add breaking change",
+				BreakingChange: "this is a breaking change",
+			},
+			error: nil,
+		},
+		{
+			name: "Breaking change footer with hyphen",
+			commit: Commit{
+				Subject: "feat: drop node 16",
+				Body:    "Some context.\n\nBREAKING-CHANGE: node 16 is no longer supported\nRefs: #12",
+			},
+			expected: ExpectedCommit{
+				Type:           "feat",
+				Breaking:       true,
+				Message:        "drop node 16",
+				BreakingChange: "node 16 is no longer supported",
+			},
+		},
+		{
+			name: "Breaking change footer followed by a paragraph",
+			commit: Commit{
+				Subject: "feat: new config",
+				Body:    "BREAKING CHANGE: config file renamed.\n\nThe old file is no longer read.",
+			},
+			expected: ExpectedCommit{
+				Type:           "feat",
+				Breaking:       true,
+				Message:        "new config",
+				BreakingChange: "config file renamed.\n\nThe old file is no longer read.",
+			},
+		},
+		{
+			name: "Breaking change footer without description",
+			commit: Commit{
+				Subject: "feat: new config",
+				Body:    "Some context.\n\nBREAKING CHANGE:\nRefs: #12",
+			},
 			expected: ExpectedCommit{
 				Type:     "feat",
-				Scope:    "scope",
 				Breaking: true,
-				Message:  "add breaking change",
+				Message:  "new config",
+			},
+		},
+		{
+			name: "Empty breaking change footer",
+			commit: Commit{
+				Subject: "feat: drop node 16",
+				Body:    "BREAKING-CHANGE: ",
+			},
+			expected: ExpectedCommit{
+				Type:     "feat",
+				Breaking: true,
+				Message:  "drop node 16",
+			},
+		},
+		{
+			name: "Breaking change mentioned in body only",
+			commit: Commit{
+				Subject: "docs: explain versioning",
+				Body:    "A BREAKING CHANGE: triggers a major release.\n\nRefs: #3",
+			},
+			expected: ExpectedCommit{
+				Type:     "docs",
+				Breaking: false,
+				Message:  "explain versioning",
 			},
-			error: nil,
 		},
 		{
 			name: "Invalid conventional commit message",
@@ -117,6 +182,7 @@ func TestPostProcess(t *testing.T) {
 			assert.Equal(t, test.expected.Scope, test.commit.Scope)
 			assert.Equal(t, test.expected.Breaking, test.commit.Breaking)
 			assert.Equal(t, test.expected.Message, test.commit.Message)
+			assert.Equal(t, test.expected.BreakingChange, test.commit.BreakingChange)
 		})
 	}
 }
@@ -162,6 +228,7 @@ func TestGetNextReleaseType(t *testing.T) {
 	tests := []struct {
 		name     string
 		commits  []*Commit
+		rules    Rules
 		expected int
 	}{
 		{
@@ -213,6 +280,22 @@ func TestGetNextReleaseType(t *testing.T) {
 			},
 			expected: semver.ReleaseTypeMajor,
 		},
+		{
+			name: "Custom rule",
+			commits: []*Commit{
+				{Type: "perf"},
+			},
+			rules:    DefaultRules.Merge(Rules{"perf": semver.ReleaseTypePatch}),
+			expected: semver.ReleaseTypePatch,
+		},
+		{
+			name: "Custom rule overriding default",
+			commits: []*Commit{
+				{Type: "feat"},
+			},
+			rules:    DefaultRules.Merge(Rules{"feat": semver.ReleaseTypeNone}),
+			expected: semver.ReleaseTypeNone,
+		},
 		{
 			name: "Major release #3",
 			commits: []*Commit{
@@ -228,8 +311,52 @@ func TestGetNextReleaseType(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := GetNextReleaseType(test.commits)
+			got := GetNextReleaseType(test.commits, test.rules)
 			assert.Equal(t, test.expected, got)
 		})
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestGroupByType(t *testing.T) {
+	commits := []*Commit{
+		{Type: "feat", Breaking: true},
+		{Type: "feat"},
+		{Type: "fix"},
+		{Type: "perf"},
+		{Type: "chore"},
+	}
+
+	grouped := GroupByType(commits, nil)
+	assert.Equal(t, []*Commit{commits[0]}, grouped[CommitTypeBreaking])
+	assert.Equal(t, []*Commit{commits[1]}, grouped[CommitTypeFeat])
+	assert.Equal(t, []*Commit{commits[2]}, grouped[CommitTypeFix])
+	assert.Equal(t, []*Commit{commits[3], commits[4]}, grouped[CommitTypeMisc])
+
+	grouped = GroupByType(commits, DefaultRules.Merge(Rules{"perf": semver.ReleaseTypePatch}))
+	assert.Equal(t, []*Commit{commits[2], commits[3]}, grouped[CommitTypeFix])
+	assert.Equal(t, []*Commit{commits[4]}, grouped[CommitTypeMisc])
+
+	grouped = GroupByType(commits, DefaultRules.Merge(Rules{"feat": semver.ReleaseTypeMajor}))
+	assert.Equal(t, []*Commit{commits[0]}, grouped[CommitTypeBreaking])
+	assert.Equal(t, []*Commit{commits[1]}, grouped[CommitTypeFeat])
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]string{"perf=patch", " revert = patch ", "deps=none"})
+	require.NoError(t, err)
+	assert.Equal(t, Rules{
+		"perf":   semver.ReleaseTypePatch,
+		"revert": semver.ReleaseTypePatch,
+		"deps":   semver.ReleaseTypeNone,
+	}, rules)
+
+	_, err = ParseRules([]string{"perf"})
+	assert.EqualError(t, err, "invalid release rule 'perf', expected '<type>=<release>'")
+
+	_, err = ParseRules([]string{"perf=tiny"})
+	assert.EqualError(t, err, "invalid release rule 'perf=tiny': invalid release type 'tiny', must be one of none, patch, minor, major")
+}
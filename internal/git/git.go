@@ -1,12 +1,16 @@
 package git
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joelvoss/release-lit/internal/semver"
 )
@@ -21,6 +25,22 @@ type GitOpts struct {
 	RootDir string
 }
 
+// ReleaseOpts configures the release commit and tag created by CreateRelease.
+type ReleaseOpts struct {
+	// NOTE(joel): Commit message format. `%s` is replaced with the version.
+	Message string
+	Author  string
+	Email   string
+	// NOTE(joel): Sign the release commit + tag and verify the signatures.
+	Sign bool
+	// NOTE(joel): Signature format (openpgp, ssh). If empty, `gpg.format` of
+	// the git config is used.
+	SignFormat string
+	// NOTE(joel): GPG key id or SSH key (path or `key::` literal). If empty,
+	// `user.signingKey` of the git config is used.
+	SigningKey string
+}
+
 // GetRootDir returns the root directory of the git repository.
 // If the current directory is not a git repository, an error is returned.
 func GetRoot(opts *GitOpts) (string, error) {
@@ -47,10 +67,101 @@ func GetRoot(opts *GitOpts) (string, error) {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+// GetBranch returns the name of the current branch. If HEAD is detached, an
+// empty string is returned.
+func GetBranch(opts *GitOpts) (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// NOTE(joel): `git symbolic-ref --quiet` exits with code 1 and without
+		// any output if HEAD is detached.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		msg := fmt.Sprintf(
+			"Error getting current branch. Reason: '%s'\n",
+			strings.TrimSpace(string(output)),
+		)
+		return "", errors.New(msg)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GetUpstream returns the upstream of the given branch, e.g. `origin/main`.
+// If the branch has no upstream or its remote-tracking branch wasn't fetched
+// yet, an empty string is returned. Only local refs are used.
+func GetUpstream(branch string, opts *GitOpts) (string, error) {
+	cmd := exec.Command(
+		"git", "for-each-ref", "--format=%(upstream:short)", "refs/heads/"+branch,
+	)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := fmt.Sprintf(
+			"Error getting upstream of branch '%s'. Reason: '%s'\n",
+			branch, strings.TrimSpace(string(output)),
+		)
+		return "", errors.New(msg)
+	}
+	upstream := strings.TrimSpace(string(output))
+	if upstream == "" {
+		return "", nil
+	}
+
+	// NOTE(joel): The upstream is read from the config, so its ref may not
+	// exist locally.
+	cmd = exec.Command("git", "rev-parse", "--quiet", "--verify", upstream)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	if err := cmd.Run(); err != nil {
+		return "", nil
+	}
+	return upstream, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GetRemoteURL returns the URL of the given remote. If the remote doesn't
+// exist, an empty string is returned.
+func GetRemoteURL(remote string, opts *GitOpts) (string, error) {
+	cmd := exec.Command("git", "remote", "get-url", remote)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// NOTE(joel): `git remote get-url` exits with code 2 if the remote
+		// doesn't exist.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+			return "", nil
+		}
+		msg := fmt.Sprintf(
+			"Error getting URL of remote '%s'. Reason: '%s'\n",
+			remote, strings.TrimSpace(string(output)),
+		)
+		return "", errors.New(msg)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 // GetTags gets all tags sorted by version in descending order, e.g. v1.0.0,
-// v0.1.0, v0.0.1.
+// v1.0.0-rc.1, v0.1.0, v0.0.1.
 func GetTags(opts *GitOpts) ([]*semver.Version, error) {
-	cmd := exec.Command("git", "tag", "--sort=-v:refname", "--merged")
+	cmd := exec.Command("git", "tag", "--merged")
 	if opts != nil && opts.RootDir != "" {
 		cmd.Dir = opts.RootDir
 	}
@@ -75,15 +186,113 @@ func GetTags(opts *GitOpts) ([]*semver.Version, error) {
 		if err == nil {
 			parsedTags = append(parsedTags, parsed)
 		} else {
-			fmt.Printf("WARN: Ignoring tag '%s'. Reason: '%s'\n", tag, err)
+			fmt.Fprintf(os.Stderr, "WARN: Ignoring tag '%s'. Reason: '%s'\n", tag, err)
 		}
 	}
 
+	// NOTE(joel): Sort by SemVer precedence instead of relying on
+	// `git tag --sort=-v:refname`, which sorts pre-releases after their
+	// release (e.g. v2.0.0-rc.1 after v2.0.0).
+	sort.Stable(sort.Reverse(semver.Collection(parsedTags)))
+
 	return parsedTags, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
+// TagExists reports whether a tag with the given name exists, regardless of
+// whether it is reachable from HEAD.
+func TagExists(tag string, opts *GitOpts) (bool, error) {
+	cmd := exec.Command("git", "rev-parse", "--quiet", "--verify", "refs/tags/"+tag)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// NOTE(joel): `git rev-parse --quiet --verify` exits with code 1 and
+		// without any output if the ref doesn't exist.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		msg := fmt.Sprintf(
+			"Error checking tag '%s'. Reason: '%s'\n",
+			tag, strings.TrimSpace(string(output)),
+		)
+		return false, errors.New(msg)
+	}
+	return true, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GetDirtyPaths returns the uncommitted changes of the working tree in the
+// short status format, e.g. ` M package.json` or `?? dist/`. Ignored files are
+// not included.
+func GetDirtyPaths(opts *GitOpts) ([]string, error) {
+	cmd := exec.Command("git", "status", "--porcelain")
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := fmt.Sprintf(
+			"Error getting status of the working tree. Reason: '%s'\n",
+			strings.TrimSpace(string(output)),
+		)
+		return nil, errors.New(msg)
+	}
+
+	paths := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GetTagsAt returns the names of all tags pointing at the given revision.
+func GetTagsAt(rev string, opts *GitOpts) ([]string, error) {
+	cmd := exec.Command("git", "tag", "--points-at", rev)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := fmt.Sprintf(
+			"Error getting tags of '%s'. Reason: '%s'\n",
+			rev, strings.TrimSpace(string(output)),
+		)
+		return nil, errors.New(msg)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// CountCommits returns the number of commits reachable from `to` but not from
+// `from`.
+func CountCommits(from string, to string, opts *GitOpts) (int, error) {
+	cmd := exec.Command("git", "rev-list", "--count", from+".."+to)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := fmt.Sprintf(
+			"Error counting commits between '%s' and '%s'. Reason: '%s'\n",
+			from, to, strings.TrimSpace(string(output)),
+		)
+		return 0, errors.New(msg)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 // GetTagHead gets the sha1 of the commit that the tag points to.
 func GetTagHead(tag string, opts *GitOpts) (string, error) {
 	if tag == "" {
@@ -108,108 +317,474 @@ func GetTagHead(tag string, opts *GitOpts) (string, error) {
 
 ////////////////////////////////////////////////////////////////////////////////
 
-// GetCommits gets all commits since the given sha.
-func GetCommits(sha string, opts *GitOpts) ([]*Commit, error) {
-	commitStr := `{"long": "%H", "short": "%h"}`
-	committerStr := `{"name": "%cN", "email": "%cE"}`
-	format := fmt.Sprintf(
-		`--pretty=format:{"sha": %s, "committer": %s, "date": "%%ci", "subject": "%%s", "body": "%%b"}==DEL==`,
-		commitStr, committerStr,
+// GetTagDate returns the date of the given tag. For annotated tags this is the
+// tagger date, for lightweight tags the committer date of the tagged commit.
+func GetTagDate(tag string, opts *GitOpts) (time.Time, error) {
+	cmd := exec.Command(
+		"git", "for-each-ref", "--format=%(creatordate:iso-strict)", "refs/tags/"+tag,
 	)
-
-	gitArgs := []string{"log", format}
-	if sha != "" {
-		sha = sha + ".."
-		gitArgs = append(gitArgs, sha)
-	}
-	cmd := exec.Command("git", gitArgs...)
 	if opts != nil && opts.RootDir != "" {
 		cmd.Dir = opts.RootDir
 	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		msg := fmt.Sprintf(
-			"Error getting commits. Reason: '%s'\n",
-			strings.TrimSpace(string(output)),
+			"Error getting date of tag '%s'. Reason: '%s'\n",
+			tag, strings.TrimSpace(string(output)),
 		)
-		return nil, errors.New(msg)
+		return time.Time{}, errors.New(msg)
 	}
 
-	// NOTE(joel): Split by '},==DEL=={'
-	splits := strings.Split(string(output), "==DEL==\n")
-
-	// NOTE(joel): Create commit structs from the splits.
-	// If there are no commits, return an empty slice.
-	commits := make([]*Commit, 0)
-	if len(splits) == 0 || (len(splits) == 1 && splits[0] == "") {
-		return commits, nil
+	date, err := time.Parse(time.RFC3339, strings.TrimSpace(string(output)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error getting date of tag '%s'. Reason: '%s'\n", tag, err)
 	}
-	for _, s := range splits {
-		s = strings.TrimSpace(s)
-		s = strings.TrimSuffix(s, "==DEL==")
-		s = strings.ReplaceAll(s, "\n", "\\n")
+	return date, nil
+}
 
-		c := &Commit{}
-		if err := json.Unmarshal([]byte(s), &c); err != nil {
-			fmt.Printf("WARN: Could not parse commit. Reason: '%s'\n", err)
-			continue
+////////////////////////////////////////////////////////////////////////////////
+
+// GetCommits gets all commits since the given sha.
+func GetCommits(sha string, opts *GitOpts) ([]*Commit, error) {
+	return GetCommitsBetween(sha, "", opts)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GetCommitsBetween gets all commits reachable from `to` but not from `from`.
+// An empty `from` means all commits up to `to`, an empty `to` means HEAD.
+func GetCommitsBetween(from string, to string, opts *GitOpts) ([]*Commit, error) {
+	gitArgs := []string{"log", "-z", "--pretty=format:" + commitFormat}
+	switch {
+	case from != "":
+		gitArgs = append(gitArgs, from+".."+to)
+	case to != "":
+		gitArgs = append(gitArgs, to)
+	}
+	cmd := exec.Command("git", gitArgs...)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	// NOTE(joel): Only read stdout, so that warnings printed by git can't end
+	// up in the parsed log.
+	output, err := cmd.Output()
+	if err != nil {
+		reason := err.Error()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			reason = strings.TrimSpace(string(exitErr.Stderr))
 		}
-		// NOTE(joel): After unmarshalling, post-process the commit and set type,
-		// scope, breaking and subject.
+		msg := fmt.Sprintf("Error getting commits. Reason: '%s'\n", reason)
+		return nil, errors.New(msg)
+	}
+
+	commits := make([]*Commit, 0)
+	for _, c := range parseCommitLog(string(output)) {
+		// NOTE(joel): Post-process the commit and set type, scope, breaking and
+		// subject.
 		if err := c.PostProcess(); err != nil {
-			fmt.Printf("WARN: Could not post-process commit. Reason: '%s'\n", err)
+			fmt.Fprintf(os.Stderr, "WARN: Could not post-process commit. Reason: '%s'\n", err)
 			continue
 		}
 		commits = append(commits, c)
 	}
 
+	// NOTE(joel): Authors (`%aN`, `%aE`) are already mapped by git, but
+	// co-authors from trailers are not.
+	applyMailmap(commits, opts)
+
 	return commits, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
-// CreateRelease creates a release commit and tags it with the given version.
-func CreateRelease(v *semver.Version, opts *GitOpts) error {
-	// Add all files to git
-	cmd := exec.Command("git", "add", ".")
+// applyMailmap maps the co-authors of the given commits to their canonical
+// name and email using the repository's `.mailmap`. Co-authors are kept as is
+// if the mapping fails.
+func applyMailmap(commits []*Commit, opts *GitOpts) {
+	contacts := make([]string, 0)
+	for _, c := range commits {
+		for _, a := range c.CoAuthors {
+			contacts = append(contacts, fmt.Sprintf("%s <%s>", a.Name, a.Email))
+		}
+	}
+	if len(contacts) == 0 {
+		return
+	}
+
+	cmd := exec.Command("git", append([]string{"check-mailmap"}, contacts...)...)
 	if opts != nil && opts.RootDir != "" {
 		cmd.Dir = opts.RootDir
 	}
-	if err := cmd.Run(); err != nil {
-		return errors.New("error adding files to git")
+	output, err := cmd.Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Could not apply mailmap. Reason: '%s'\n", err)
+		return
 	}
 
-	// Create commit and add any changed files
-	commitMsg := fmt.Sprintf(releaseMessage, v.ToString())
-	cmd = exec.Command("git", "commit", "--allow-empty", "-m", commitMsg)
-	cmd.Env = []string{
-		fmt.Sprintf("GIT_AUTHOR_NAME=%s", releaseAuthor),
-		fmt.Sprintf("GIT_AUTHOR_EMAIL=%s", releaseEmail),
-		fmt.Sprintf("GIT_COMMITTER_NAME=%s", releaseAuthor),
-		fmt.Sprintf("GIT_COMMITTER_EMAIL=%s", releaseEmail),
+	mapped := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(mapped) != len(contacts) {
+		return
+	}
+	i := 0
+	for _, c := range commits {
+		for j := range c.CoAuthors {
+			if person, ok := parsePerson(mapped[i]); ok {
+				c.CoAuthors[j] = person
+			}
+			i++
+		}
 	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NOTE(joel): Fields of a commit are separated by NUL (%x00) and every commit
+// is terminated by a record separator (%x1e). With `-z`, git additionally
+// separates commits with NUL instead of a newline. Neither byte can appear in
+// a commit message, so no escaping is needed.
+const (
+	fieldSeparator  = "\x00"
+	recordSeparator = "\x1e"
+)
+
+var commitFields = []string{"%H", "%h", "%aN", "%aE", "%cN", "%cE", "%ci", "%s", "%b"}
+
+var commitFormat = strings.Join(commitFields, "%x00") + "%x1e"
+
+// parseCommitLog parses the output of `git log -z` with commitFormat into
+// commits. Malformed records are skipped with a warning.
+func parseCommitLog(output string) []*Commit {
+	commits := make([]*Commit, 0)
+	for _, record := range strings.Split(output, recordSeparator) {
+		record = strings.TrimLeft(record, fieldSeparator+"\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, fieldSeparator, len(commitFields))
+		if len(fields) != len(commitFields) {
+			fmt.Fprintf(
+				os.Stderr,
+				"WARN: Could not parse commit. Reason: 'expected %d fields, got %d'\n",
+				len(commitFields), len(fields),
+			)
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.ReplaceAll(fields[i], "\r\n", "\n")
+		}
+
+		commits = append(commits, &Commit{
+			Sha:       Sha{Long: fields[0], Short: fields[1]},
+			Author:    Committer{Name: fields[2], Email: fields[3]},
+			Committer: Committer{Name: fields[4], Email: fields[5]},
+			Date:      fields[6],
+			Subject:   strings.TrimSpace(fields[7]),
+			Body:      strings.TrimSpace(fields[8]),
+		})
+	}
+	return commits
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// CreateRelease creates a release commit of the given files and tags it with
+// the given version. Only these files are committed, other changes of the
+// working tree or the index are left untouched. If ropts is nil or one of its
+// fields is empty, the default release message and bot identity are used.
+func CreateRelease(v *semver.Version, files []string, ropts *ReleaseOpts, opts *GitOpts) error {
+	if err := CreateCommit(v, files, ropts, opts); err != nil {
+		return err
+	}
+	return CreateTag(v, ropts, opts)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// CreateCommit adds the given files and creates the release commit of only
+// these files (see CreateRelease).
+func CreateCommit(v *semver.Version, files []string, ropts *ReleaseOpts, opts *GitOpts) error {
+	message, env := releaseIdentity(ropts)
+
+	// Add the changed files to git. New files (e.g. the first changelog) must
+	// be known to git before they can be committed with `--only`.
+	if len(files) > 0 {
+		cmd := exec.Command("git", slices.Concat([]string{"add", "--"}, files)...)
+		if opts != nil && opts.RootDir != "" {
+			cmd.Dir = opts.RootDir
+		}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			msg := fmt.Sprintf(
+				"Error adding files to git. Reason: '%s'\n",
+				strings.TrimSpace(string(output)),
+			)
+			return errors.New(msg)
+		}
+	}
+
+	// Create commit of only the changed files
+	commitMsg := fmt.Sprintf(message, v.ToString())
+	args := []string{"commit", "--allow-empty", "--only", "-m", commitMsg}
+	if ropts != nil && ropts.Sign {
+		args = slices.Concat(signConfig(ropts), args, []string{"-S"})
+	} else {
+		// NOTE(joel): The environment is inherited, so a `commit.gpgSign` of
+		// the user's git config must not sign an unsigned release.
+		args = append(args, "--no-gpg-sign")
+	}
+	cmd := exec.Command("git", slices.Concat(args, []string{"--"}, files)...)
+	cmd.Env = env
 	if opts != nil && opts.RootDir != "" {
 		cmd.Dir = opts.RootDir
 	}
-	if err := cmd.Run(); err != nil {
-		return errors.New("error creating release commit")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		kind := "release commit"
+		if ropts != nil && ropts.Sign {
+			kind = "signed release commit"
+		}
+		msg := fmt.Sprintf(
+			"Error creating %s. Reason: '%s'\n",
+			kind, strings.TrimSpace(string(output)),
+		)
+		return errors.New(msg)
+	}
+
+	if ropts != nil && ropts.Sign {
+		return VerifyCommit("HEAD", ropts, opts)
 	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// CreateTag creates the annotated release tag of the given version on HEAD.
+func CreateTag(v *semver.Version, ropts *ReleaseOpts, opts *GitOpts) error {
+	_, env := releaseIdentity(ropts)
 
-	// Tag the commit
 	versionStr := fmt.Sprintf("v%s", v.ToString())
-	cmd = exec.Command("git", "tag", "-a", versionStr, "-m", versionStr)
-	cmd.Env = []string{
-		fmt.Sprintf("GIT_AUTHOR_NAME=%s", releaseAuthor),
-		fmt.Sprintf("GIT_AUTHOR_EMAIL=%s", releaseEmail),
-		fmt.Sprintf("GIT_COMMITTER_NAME=%s", releaseAuthor),
-		fmt.Sprintf("GIT_COMMITTER_EMAIL=%s", releaseEmail),
+	// NOTE(joel): Same as for the release commit, a `tag.gpgSign` of the
+	// user's git config must not sign an unsigned release tag.
+	args := []string{"tag", "-a", "--no-sign", versionStr, "-m", versionStr}
+	if ropts != nil && ropts.Sign {
+		args = slices.Concat(signConfig(ropts), []string{"tag", "-s", versionStr, "-m", versionStr})
 	}
+	cmd := exec.Command("git", args...)
+	cmd.Env = env
 	if opts != nil && opts.RootDir != "" {
 		cmd.Dir = opts.RootDir
 	}
-	if err := cmd.Run(); err != nil {
-		return errors.New("error creating release tag")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		kind := "release tag"
+		if ropts != nil && ropts.Sign {
+			kind = "signed release tag"
+		}
+		msg := fmt.Sprintf(
+			"Error creating %s. Reason: '%s'\n",
+			kind, strings.TrimSpace(string(output)),
+		)
+		return errors.New(msg)
+	}
+
+	if ropts != nil && ropts.Sign {
+		return VerifyTag(versionStr, ropts, opts)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// releaseIdentity returns the commit message format and the environment with
+// the bot identity of a release. Empty fields of ropts fall back to the
+// defaults.
+func releaseIdentity(ropts *ReleaseOpts) (string, []string) {
+	message := releaseMessageOf(ropts)
+	author, email := releaseAuthorOf(ropts)
+	// NOTE(joel): The environment is inherited, since signing needs e.g. HOME
+	// or GNUPGHOME to find the keys. Later entries take precedence. Unsigned
+	// releases opt out of the signing configs explicitly (see CreateCommit
+	// and CreateTag).
+	env := append(
+		os.Environ(),
+		fmt.Sprintf("GIT_AUTHOR_NAME=%s", author),
+		fmt.Sprintf("GIT_AUTHOR_EMAIL=%s", email),
+		fmt.Sprintf("GIT_COMMITTER_NAME=%s", author),
+		fmt.Sprintf("GIT_COMMITTER_EMAIL=%s", email),
+	)
+	return message, env
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ReleaseSubject returns the subject of the release commit of v, i.e. the
+// first line of its commit message.
+func ReleaseSubject(v *semver.Version, ropts *ReleaseOpts) string {
+	message := fmt.Sprintf(releaseMessageOf(ropts), v.ToString())
+	subject, _, _ := strings.Cut(message, "\n")
+	return subject
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// releaseMessageOf returns the commit message format of a release. An empty
+// message of ropts falls back to the default.
+func releaseMessageOf(ropts *ReleaseOpts) string {
+	if ropts != nil && ropts.Message != "" {
+		return ropts.Message
+	}
+	return releaseMessage
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// releaseAuthorOf returns the name and email of the author of a release.
+// Empty fields of ropts fall back to the bot identity.
+func releaseAuthorOf(ropts *ReleaseOpts) (string, string) {
+	author, email := releaseAuthor, releaseEmail
+	if ropts != nil {
+		if ropts.Author != "" {
+			author = ropts.Author
+		}
+		if ropts.Email != "" {
+			email = ropts.Email
+		}
+	}
+	return author, email
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GetHead returns the sha of HEAD. If the current branch has no commits yet,
+// an empty string is returned.
+func GetHead(opts *GitOpts) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD")
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		// NOTE(joel): `--quiet` exits with 1 and no output if HEAD is unborn.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		msg := fmt.Sprintf("Error getting HEAD. Reason: '%s'\n", err)
+		return "", errors.New(msg)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ResetSoft moves the current branch to the given commit, keeping the index
+// and the working tree. If sha is empty, the branch is reset to having no
+// commits at all.
+func ResetSoft(sha string, opts *GitOpts) error {
+	args := []string{"reset", "--soft", sha}
+	if sha == "" {
+		args = []string{"update-ref", "-d", "HEAD"}
+	}
+	cmd := exec.Command("git", args...)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		msg := fmt.Sprintf(
+			"Error resetting to '%s'. Reason: '%s'\n",
+			sha, strings.TrimSpace(string(output)),
+		)
+		return errors.New(msg)
 	}
 
 	return nil
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Unstage resets the index entries of the given files to HEAD. Files that
+// are not part of HEAD are removed from the index.
+func Unstage(files []string, opts *GitOpts) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	cmd := exec.Command("git", slices.Concat([]string{"reset", "--quiet", "--"}, files)...)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		msg := fmt.Sprintf(
+			"Error unstaging files. Reason: '%s'\n",
+			strings.TrimSpace(string(output)),
+		)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// DeleteTag deletes the given local tag.
+func DeleteTag(tag string, opts *GitOpts) error {
+	cmd := exec.Command("git", "tag", "--delete", tag)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		msg := fmt.Sprintf(
+			"Error deleting tag '%s'. Reason: '%s'\n",
+			tag, strings.TrimSpace(string(output)),
+		)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Push pushes the given branch and tag to the remote atomically, i.e. either
+// both refs are updated or none. It never force-pushes: if the remote branch
+// has commits that are missing locally, a descriptive error is returned.
+// Other failures (e.g. a flaky network) are retried once.
+func Push(remote string, branch string, tag string, opts *GitOpts) error {
+	args := []string{
+		"push", "--atomic", "--porcelain", remote,
+		fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch),
+		fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag),
+	}
+
+	var output []byte
+	var err error
+	for range 2 {
+		cmd := exec.Command("git", args...)
+		if opts != nil && opts.RootDir != "" {
+			cmd.Dir = opts.RootDir
+		}
+		output, err = cmd.CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		// NOTE(joel): Rejected refs are marked with `!` in porcelain mode.
+		// Retrying won't help, since the remote refs don't change by
+		// themselves.
+		if strings.Contains(string(output), "non-fast-forward") || strings.Contains(string(output), "fetch first") {
+			msg := fmt.Sprintf(
+				"Error pushing to '%s'. Branch '%s' is behind the remote branch (non-fast-forward), so nothing was pushed. Pull the changes first\n",
+				remote, branch,
+			)
+			return errors.New(msg)
+		}
+		if strings.Contains(string(output), "[rejected]") || strings.Contains(string(output), "[remote rejected]") {
+			break
+		}
+	}
+
+	msg := fmt.Sprintf(
+		"Error pushing to '%s'. Reason: '%s'\n",
+		remote, strings.TrimSpace(string(output)),
+	)
+	return errors.New(msg)
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/joelvoss/release-lit/internal/semver"
 
@@ -99,6 +101,50 @@ func TestGetRoot(t *testing.T) {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+func TestGetBranch(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	shas := createCommits(t, cwd, []TestCommit{{Msg: "Commit #1"}})
+
+	cmd := exec.Command("git", "checkout", "-b", "release/1.x")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	branch, err := GetBranch(&GitOpts{RootDir: cwd})
+	assert.NoError(t, err)
+	assert.Equal(t, "release/1.x", branch)
+
+	cmd = exec.Command("git", "checkout", "--detach", shas[0])
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	branch, err = GetBranch(&GitOpts{RootDir: cwd})
+	assert.NoError(t, err)
+	assert.Equal(t, "", branch)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestGetRemoteURL(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	url, err := GetRemoteURL("origin", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "", url)
+
+	cmd := exec.Command("git", "remote", "add", "origin", "git@github.com:owner/repo.git")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	url, err = GetRemoteURL("origin", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "git@github.com:owner/repo.git", url)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 func TestGetTags(t *testing.T) {
 	cwd, cleanUp := createGitRepo(t)
 	defer cleanUp()
@@ -121,6 +167,32 @@ func TestGetTags(t *testing.T) {
 	assert.Equal(t, "v0.0.1", tags[2].Original)
 }
 
+func TestGetTagsPrerelease(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	commits := []TestCommit{
+		{Msg: "Commit #1", Tag: "v1.0.0"},
+		{Msg: "Commit #2", Tag: "v2.0.0-rc.1"},
+		{Msg: "Commit #3", Tag: "v2.0.0-rc.2"},
+		{Msg: "Commit #4", Tag: "v2.0.0-rc.10"},
+		{Msg: "Commit #5", Tag: "v2.0.0"},
+		{Msg: "Commit #6", Tag: "not-a-version"},
+	}
+	createCommits(t, cwd, commits)
+
+	tags, err := GetTags(&GitOpts{RootDir: cwd})
+	assert.NoError(t, err)
+
+	originals := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		originals = append(originals, tag.Original)
+	}
+	assert.Equal(t, []string{
+		"v2.0.0", "v2.0.0-rc.10", "v2.0.0-rc.2", "v2.0.0-rc.1", "v1.0.0",
+	}, originals)
+}
+
 func TestGetTags2(t *testing.T) {
 	cwd, cleanUp := createGitRepo(t)
 	defer cleanUp()
@@ -140,6 +212,50 @@ func TestGetTags2(t *testing.T) {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+func TestTagExists(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1", Tag: "v0.1.0"}})
+
+	exists, err := TagExists("v0.1.0", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = TagExists("v0.2.0", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestGetDirtyPaths(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	require.NoError(t, os.WriteFile(path.Join(cwd, ".gitignore"), []byte("dist/\n"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(cwd, "a.txt"), []byte("a"), 0644))
+	cmd := exec.Command("git", "add", ".")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+	createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1"}})
+
+	dirty, err := GetDirtyPaths(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Empty(t, dirty)
+
+	require.NoError(t, os.WriteFile(path.Join(cwd, "a.txt"), []byte("changed"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(cwd, "b.txt"), []byte("b"), 0644))
+	require.NoError(t, os.Mkdir(path.Join(cwd, "dist"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(cwd, "dist", "app"), []byte("app"), 0644))
+
+	dirty, err = GetDirtyPaths(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, []string{" M a.txt", "?? b.txt"}, dirty)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 func TestGetTagHead(t *testing.T) {
 	cwd, cleanUp := createGitRepo(t)
 	defer cleanUp()
@@ -190,6 +306,10 @@ func TestGetCommits(t *testing.T) {
 				Short: expectedShas[3][:7],
 				Long:  expectedShas[3],
 			},
+			Author: Committer{
+				Name:  "Test User",
+				Email: "test.user@example.com",
+			},
 			Committer: Committer{
 				Name:  "Test User",
 				Email: "test.user@example.com",
@@ -206,6 +326,10 @@ func TestGetCommits(t *testing.T) {
 				Short: expectedShas[2][:7],
 				Long:  expectedShas[2],
 			},
+			Author: Committer{
+				Name:  "Test User",
+				Email: "test.user@example.com",
+			},
 			Committer: Committer{
 				Name:  "Test User",
 				Email: "test.user@example.com",
@@ -222,6 +346,58 @@ func TestGetCommits(t *testing.T) {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+func TestGetCommitsBetween(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	createCommits(t, cwd, []TestCommit{
+		{Msg: "feat: commit #1", Tag: "v0.1.0"},
+		{Msg: "feat: commit #2"},
+		{Msg: "feat: commit #3", Tag: "v0.2.0"},
+		{Msg: "feat: commit #4"},
+	})
+
+	commits, err := GetCommitsBetween("v0.1.0", "v0.2.0", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "commit #3", commits[0].Message)
+	assert.Equal(t, "commit #2", commits[1].Message)
+
+	commits, err = GetCommitsBetween("", "v0.1.0", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "commit #1", commits[0].Message)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestGetTagDate(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1", Tag: "v0.1.0"}})
+
+	cmd := exec.Command("git", "tag", "-a", "v0.2.0", "-m", "v0.2.0")
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2024-03-04T10:00:00+02:00")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	// NOTE(joel): Lightweight tags use the commit date, annotated tags the
+	// tagger date.
+	date, err := GetTagDate("v0.1.0", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01T12:00:00-01:00", date.Format(time.RFC3339))
+
+	date, err = GetTagDate("v0.2.0", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-04T10:00:00+02:00", date.Format(time.RFC3339))
+
+	_, err = GetTagDate("v9.9.9", &GitOpts{RootDir: cwd})
+	assert.Error(t, err)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 func TestGetCommitsNoCommits(t *testing.T) {
 	cwd, cleanUp := createGitRepo(t)
 	defer cleanUp()
@@ -236,12 +412,118 @@ func TestGetCommitsNoCommits(t *testing.T) {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+func TestGetCommitsSpecialCharacters(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	messages := []string{
+		"chore: initial commit",
+		"fix: handle \"quoted\" paths with back\\slashes\tand tabs",
+		"feat(ui): add 🚀 launch button\n\nBody with ✨ emoji and {\"json\": true}",
+		"fix: windows line endings\r\n\r\nFirst line\r\nSecond line\r\n",
+		"feat: empty body",
+	}
+	for _, msg := range messages {
+		// NOTE(joel): Keep the message verbatim, so that CRLF line endings are
+		// not stripped by git.
+		cmd := exec.Command("git", "commit", "--allow-empty", "--cleanup=verbatim", "-m", msg)
+		cmd.Dir = cwd
+		require.NoError(t, cmd.Run())
+	}
+
+	commits, err := GetCommits("", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	require.Len(t, commits, 5)
+
+	assert.Equal(t, "feat", commits[0].Type)
+	assert.Equal(t, "empty body", commits[0].Message)
+	assert.Equal(t, "", commits[0].Body)
+
+	assert.Equal(t, "fix", commits[1].Type)
+	assert.Equal(t, "windows line endings", commits[1].Message)
+	assert.Equal(t, "First line\nSecond line", commits[1].Body)
+
+	assert.Equal(t, "ui", commits[2].Scope)
+	assert.Equal(t, "add 🚀 launch button", commits[2].Message)
+	assert.Equal(t, "Body with ✨ emoji and {\"json\": true}", commits[2].Body)
+
+	assert.Equal(t, "handle \"quoted\" paths with back\\slashes\tand tabs", commits[3].Message)
+
+	assert.Equal(t, "chore", commits[4].Type)
+	assert.Equal(t, "", commits[4].Body)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestGetCommitsAuthors(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	err := os.WriteFile(path.Join(cwd, ".mailmap"), []byte(
+		"Jane Doe <jane@example.com> <jane@old.example.com>\n",
+	), 0644)
+	require.NoError(t, err)
+
+	cmd := exec.Command(
+		"git", "commit", "--allow-empty",
+		"-m", "feat: squashed feature\n\nCo-authored-by: J. Doe <jane@old.example.com>\nCo-authored-by: John <john@example.com>",
+	)
+	cmd.Env = append(
+		os.Environ(),
+		"GIT_AUTHOR_NAME=Jane", "GIT_AUTHOR_EMAIL=jane@old.example.com",
+		"GIT_COMMITTER_NAME=Forge Bot", "GIT_COMMITTER_EMAIL=bot@example.com",
+	)
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	commits, err := GetCommits("", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+
+	assert.Equal(t, Committer{Name: "Jane Doe", Email: "jane@example.com"}, commits[0].Author)
+	assert.Equal(t, Committer{Name: "Forge Bot", Email: "bot@example.com"}, commits[0].Committer)
+	assert.Equal(t, []Committer{
+		{Name: "Jane Doe", Email: "jane@example.com"},
+		{Name: "John", Email: "john@example.com"},
+	}, commits[0].CoAuthors)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestParseCommitLog(t *testing.T) {
+	output := "a1\x00a\x00Jane\x00jane@example.com\x00Bot\x00bot@example.com\x002024-01-01 12:00:00 +0000\x00feat: one\x00\x1e\x00" +
+		"b2\x00b\x00John\x00john@example.com\x00Bot\x00bot@example.com\x002024-01-02 12:00:00 +0000\x00fix: two\x00Body\r\nline\n\x1e\x00" +
+		"broken\x00record\x1e"
+
+	commits := parseCommitLog(output)
+	assert.Equal(t, []*Commit{
+		{
+			Sha:       Sha{Long: "a1", Short: "a"},
+			Author:    Committer{Name: "Jane", Email: "jane@example.com"},
+			Committer: Committer{Name: "Bot", Email: "bot@example.com"},
+			Date:      "2024-01-01 12:00:00 +0000",
+			Subject:   "feat: one",
+		},
+		{
+			Sha:       Sha{Long: "b2", Short: "b"},
+			Author:    Committer{Name: "John", Email: "john@example.com"},
+			Committer: Committer{Name: "Bot", Email: "bot@example.com"},
+			Date:      "2024-01-02 12:00:00 +0000",
+			Subject:   "fix: two",
+			Body:      "Body\nline",
+		},
+	}, commits)
+	assert.Empty(t, parseCommitLog(""))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 func TestCreateRelease(t *testing.T) {
 	cwd, cleanUp := createGitRepo(t)
 	defer cleanUp()
 
 	v, _ := semver.Parse("v2.0.0")
-	err := CreateRelease(v, &GitOpts{RootDir: cwd})
+	err := CreateRelease(v, nil, nil, &GitOpts{RootDir: cwd})
 
 	require.NoError(t, err)
 
@@ -262,3 +544,272 @@ func TestCreateRelease(t *testing.T) {
 	assert.Contains(t, string(output), "Tagger: release-lit-bot <bot@release-lit>")
 	assert.Contains(t, string(output), "Author: release-lit-bot <bot@release-lit>")
 }
+
+func TestCreateReleaseCustomIdentity(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	v, _ := semver.Parse("v2.0.0")
+	err := CreateRelease(v, nil, &ReleaseOpts{
+		Message: "release: %s",
+		Author:  "Release Bot",
+		Email:   "release@example.com",
+	}, &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+
+	cmd := exec.Command("git", "show", "v2.0.0")
+	cmd.Dir = cwd
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "release: 2.0.0")
+	assert.Contains(t, string(output), "Tagger: Release Bot <release@example.com>")
+	assert.Contains(t, string(output), "Author: Release Bot <release@example.com>")
+}
+
+func TestReleaseSubject(t *testing.T) {
+	v, _ := semver.Parse("v2.0.0-rc.1")
+	assert.Equal(t, "chore(release): v2.0.0-rc.1", ReleaseSubject(v, nil))
+	assert.Equal(t, "release: 2.0.0-rc.1", ReleaseSubject(v, &ReleaseOpts{Message: "release: %s\n\nSigned-off-by: Bot"}))
+}
+
+func TestCreateReleaseSigningConfig(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	// NOTE(joel): The signing configs of the user don't apply to an unsigned
+	// release. Signing would fail without a key.
+	for _, config := range []string{"commit.gpgSign", "tag.gpgSign"} {
+		cmd := exec.Command("git", "config", config, "true")
+		cmd.Dir = cwd
+		require.NoError(t, cmd.Run())
+	}
+
+	v, _ := semver.Parse("v2.0.0")
+	err := CreateRelease(v, nil, nil, &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+
+	// NOTE(joel): Errors carry the output of git.
+	err = CreateTag(v, nil, &GitOpts{RootDir: cwd})
+	assert.EqualError(t, err, "Error creating release tag. Reason: 'fatal: tag 'v2.0.0' already exists'\n")
+}
+
+func TestCreateReleaseOnlyFiles(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1"}})
+	for _, name := range []string{"CHANGELOG.md", "staged.txt", "stray.txt"} {
+		require.NoError(t, os.WriteFile(path.Join(cwd, name), []byte(name), 0644))
+	}
+	cmd := exec.Command("git", "add", "staged.txt")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	v, _ := semver.Parse("v2.0.0")
+	err := CreateRelease(v, []string{path.Join(cwd, "CHANGELOG.md")}, nil, &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+
+	cmd = exec.Command("git", "show", "--format=", "--name-only", "v2.0.0^{commit}")
+	cmd.Dir = cwd
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "CHANGELOG.md", strings.TrimSpace(string(output)))
+
+	// NOTE(joel): Other changes, staged or not, are left untouched.
+	dirty, err := GetDirtyPaths(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A  staged.txt", "?? stray.txt"}, dirty)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestGetHead(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	head, err := GetHead(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "", head)
+
+	shas := createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1"}})
+	head, err = GetHead(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, shas[0], head)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestResetSoft(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	shas := createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1"}, {Msg: "feat: commit #2"}})
+	require.NoError(t, ResetSoft(shas[0], &GitOpts{RootDir: cwd}))
+	head, err := GetHead(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, shas[0], head)
+
+	require.NoError(t, ResetSoft("", &GitOpts{RootDir: cwd}))
+	head, err = GetHead(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "", head)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestUnstage(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	require.NoError(t, os.WriteFile(path.Join(cwd, "a.txt"), []byte("a"), 0644))
+	cmd := exec.Command("git", "add", "a.txt")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+	createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1"}})
+
+	require.NoError(t, os.WriteFile(path.Join(cwd, "a.txt"), []byte("changed"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(cwd, "b.txt"), []byte("b"), 0644))
+	cmd = exec.Command("git", "add", "a.txt", "b.txt")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	require.NoError(t, Unstage([]string{"a.txt", "b.txt"}, &GitOpts{RootDir: cwd}))
+	dirty, err := GetDirtyPaths(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, []string{" M a.txt", "?? b.txt"}, dirty)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestDeleteTag(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1", Tag: "v0.1.0"}})
+	require.NoError(t, DeleteTag("v0.1.0", &GitOpts{RootDir: cwd}))
+	exists, err := TagExists("v0.1.0", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.Error(t, DeleteTag("v0.1.0", &GitOpts{RootDir: cwd}))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestGetUpstream(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1"}})
+	branch, err := GetBranch(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+
+	upstream, err := GetUpstream(branch, &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "", upstream)
+
+	for _, args := range [][]string{
+		{"remote", "add", "origin", "/does/not/exist"},
+		{"config", "branch." + branch + ".remote", "origin"},
+		{"config", "branch." + branch + ".merge", "refs/heads/" + branch},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = cwd
+		require.NoError(t, cmd.Run())
+	}
+
+	// NOTE(joel): The remote-tracking branch wasn't fetched yet.
+	upstream, err = GetUpstream(branch, &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "", upstream)
+
+	cmd := exec.Command("git", "update-ref", "refs/remotes/origin/"+branch, "HEAD")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	upstream, err = GetUpstream(branch, &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "origin/"+branch, upstream)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestGetTagsAt(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	createCommits(t, cwd, []TestCommit{
+		{Msg: "feat: commit #1", Tag: "v0.1.0"},
+		{Msg: "feat: commit #2", Tag: "v0.2.0"},
+	})
+	cmd := exec.Command("git", "tag", "latest")
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+
+	tags, err := GetTagsAt("HEAD", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"latest", "v0.2.0"}, tags)
+
+	tags, err = GetTagsAt("HEAD~1", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.1.0"}, tags)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestCountCommits(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	shas := createCommits(t, cwd, []TestCommit{
+		{Msg: "feat: commit #1"},
+		{Msg: "feat: commit #2"},
+		{Msg: "feat: commit #3"},
+	})
+
+	count, err := CountCommits(shas[0], "HEAD", &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = CountCommits("HEAD", shas[0], &GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	_, err = CountCommits("HEAD", "unknown", &GitOpts{RootDir: cwd})
+	assert.Error(t, err)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestPush(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	remote := t.TempDir()
+	cmd := exec.Command("git", "init", "--bare")
+	cmd.Dir = remote
+	require.NoError(t, cmd.Run())
+
+	shas := createCommits(t, cwd, []TestCommit{{Msg: "feat: commit #1", Tag: "v0.1.0"}, {Msg: "feat: commit #2", Tag: "v0.2.0"}})
+	branch, err := GetBranch(&GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+
+	require.NoError(t, Push(remote, branch, "v0.2.0", &GitOpts{RootDir: cwd}))
+	cmd = exec.Command("git", "show-ref")
+	cmd.Dir = remote
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(
+		"%s refs/heads/%s\n%s refs/tags/v0.2.0\n", shas[1], branch, shas[1],
+	), string(output))
+
+	// NOTE(joel): A conflicting tag rejects the whole push.
+	cmd = exec.Command("git", "tag", "--force", "v0.2.0", shas[0])
+	cmd.Dir = cwd
+	require.NoError(t, cmd.Run())
+	err = Push(remote, branch, "v0.2.0", &GitOpts{RootDir: cwd})
+	assert.ErrorContains(t, err, "[rejected]")
+
+	err = Push(path.Join(remote, "missing"), branch, "v0.2.0", &GitOpts{RootDir: cwd})
+	assert.ErrorContains(t, err, "Error pushing to")
+}
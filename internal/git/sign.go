@@ -0,0 +1,175 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// NOTE(joel): Supported signature formats, named after the values of the
+// `gpg.format` git config.
+const (
+	SignFormatOpenPGP = "openpgp"
+	SignFormatSSH     = "ssh"
+)
+
+var SignFormats = []string{SignFormatOpenPGP, SignFormatSSH}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// signConfig returns the `-c` arguments that override the signature format
+// and key of the git config (if set).
+func signConfig(ropts *ReleaseOpts) []string {
+	var args []string
+	if ropts.SignFormat != "" {
+		args = append(args, "-c", "gpg.format="+ropts.SignFormat)
+	}
+	if ropts.SigningKey != "" {
+		args = append(args, "-c", "user.signingKey="+ropts.SigningKey)
+	}
+	return args
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// VerifyCommit verifies the signature of the given commit.
+func VerifyCommit(rev string, ropts *ReleaseOpts, opts *GitOpts) error {
+	return verify("verify-commit", rev, ropts, opts)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// VerifyTag verifies the signature of the given tag.
+func VerifyTag(tag string, ropts *ReleaseOpts, opts *GitOpts) error {
+	return verify("verify-tag", tag, ropts, opts)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// verify runs the given verify command. SSH signatures can only be verified
+// against a list of allowed signers. If `gpg.ssh.allowedSignersFile` isn't
+// configured, a temporary one is created that allows the signing key.
+func verify(command string, rev string, ropts *ReleaseOpts, opts *GitOpts) error {
+	args := signConfig(ropts)
+
+	format := ropts.SignFormat
+	if format == "" {
+		format = getConfig("gpg.format", opts)
+	}
+	if format == SignFormatSSH && getConfig("gpg.ssh.allowedSignersFile", opts) == "" {
+		file, err := allowedSigners(ropts, opts)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(file)
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+file)
+	}
+
+	cmd := exec.Command("git", slices.Concat(args, []string{command, rev})...)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		msg := fmt.Sprintf(
+			"Error verifying signature of '%s'. Reason: '%s'\n",
+			rev, strings.TrimSpace(string(output)),
+		)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// allowedSigners writes a temporary allowed signers file with the public key
+// of the SSH signing key and returns its path.
+func allowedSigners(ropts *ReleaseOpts, opts *GitOpts) (string, error) {
+	key := ropts.SigningKey
+	if key == "" {
+		key = getConfig("user.signingKey", opts)
+	}
+	if key == "" {
+		return "", errors.New("No SSH signing key found. Set 'user.signingKey' in the git config or pass a signing key")
+	}
+	publicKey, err := sshPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	_, email := releaseAuthorOf(ropts)
+	f, err := os.CreateTemp("", "release-lit-allowed-signers-*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "%s %s\n", email, publicKey); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// sshPublicKey returns the public key of the SSH signing key. Like git, the
+// key is either a `key::` literal or the path of a public or private key
+// file. For private keys, the `.pub` file next to it is used if it exists.
+func sshPublicKey(key string) (string, error) {
+	if literal, ok := strings.CutPrefix(key, "key::"); ok {
+		return strings.TrimSpace(literal), nil
+	}
+	if strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "ecdsa-") {
+		return strings.TrimSpace(key), nil
+	}
+
+	if rest, ok := strings.CutPrefix(key, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		key = filepath.Join(home, rest)
+	}
+	if !strings.HasSuffix(key, ".pub") {
+		if _, err := os.Stat(key + ".pub"); err == nil {
+			key += ".pub"
+		}
+	}
+	if strings.HasSuffix(key, ".pub") {
+		content, err := os.ReadFile(key)
+		if err != nil {
+			return "", fmt.Errorf("Error reading SSH public key. Reason: '%s'", err)
+		}
+		return strings.TrimSpace(string(content)), nil
+	}
+
+	output, err := exec.Command("ssh-keygen", "-y", "-f", key).CombinedOutput()
+	if err != nil {
+		msg := fmt.Sprintf(
+			"Error reading SSH public key of '%s'. Reason: '%s'\n",
+			key, strings.TrimSpace(string(output)),
+		)
+		return "", errors.New(msg)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// getConfig returns the value of the given git config key or an empty string
+// if it isn't set.
+func getConfig(key string, opts *GitOpts) string {
+	cmd := exec.Command("git", "config", "--get", key)
+	if opts != nil && opts.RootDir != "" {
+		cmd.Dir = opts.RootDir
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
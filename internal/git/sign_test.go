@@ -0,0 +1,111 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createSSHKey(t *testing.T, dir string, name string) string {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	key := path.Join(dir, name)
+	cmd := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", name, "-f", key)
+	require.NoError(t, cmd.Run())
+	return key
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestCreateReleaseSignedSSH(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	key := createSSHKey(t, t.TempDir(), "release")
+	v, _ := semver.Parse("v2.0.0")
+	ropts := &ReleaseOpts{Sign: true, SignFormat: SignFormatSSH, SigningKey: key}
+	require.NoError(t, CreateRelease(v, nil, ropts, &GitOpts{RootDir: cwd}))
+
+	// NOTE(joel): Both signatures are verified against the signing key only.
+	require.NoError(t, VerifyCommit("HEAD", ropts, &GitOpts{RootDir: cwd}))
+	require.NoError(t, VerifyTag("v2.0.0", ropts, &GitOpts{RootDir: cwd}))
+
+	cmd := exec.Command("git", "cat-file", "tag", "v2.0.0")
+	cmd.Dir = cwd
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "-----BEGIN SSH SIGNATURE-----")
+
+	// NOTE(joel): Another key doesn't verify the signature.
+	other := createSSHKey(t, t.TempDir(), "other")
+	err = VerifyTag("v2.0.0", &ReleaseOpts{SignFormat: SignFormatSSH, SigningKey: other}, &GitOpts{RootDir: cwd})
+	assert.ErrorContains(t, err, "Error verifying signature of 'v2.0.0'")
+}
+
+func TestCreateReleaseSignedGPG(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	// NOTE(joel): Use a throwaway keyring. The socket of the gpg-agent must
+	// fit into a short path, so the temp dir of the test is too long.
+	home, err := os.MkdirTemp("", "gpg-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(home)
+	t.Setenv("GNUPGHOME", home)
+	defer exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+
+	cmd := exec.Command(
+		"gpg", "--batch", "--passphrase", "", "--quick-gen-key",
+		"Release Bot <release@example.com>", "ed25519", "sign", "never",
+	)
+	require.NoError(t, cmd.Run())
+
+	v, _ := semver.Parse("v2.0.0")
+	ropts := &ReleaseOpts{Sign: true, SignFormat: SignFormatOpenPGP, SigningKey: "release@example.com"}
+	require.NoError(t, CreateRelease(v, nil, ropts, &GitOpts{RootDir: cwd}))
+
+	cmd = exec.Command("git", "log", "-1", "--format=%G?")
+	cmd.Dir = cwd
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "G\n", string(output))
+
+	// NOTE(joel): An unknown key fails before anything is created.
+	v, _ = semver.Parse("v3.0.0")
+	ropts.SigningKey = "unknown@example.com"
+	err = CreateRelease(v, nil, ropts, &GitOpts{RootDir: cwd})
+	assert.ErrorContains(t, err, "Error creating signed release commit")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestSSHPublicKey(t *testing.T) {
+	dir := t.TempDir()
+	key := createSSHKey(t, dir, "release")
+	content, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+	expected := string(content[:len(content)-1])
+
+	for _, k := range []string{key, key + ".pub", "key::" + expected, expected} {
+		publicKey, err := sshPublicKey(k)
+		require.NoError(t, err)
+		assert.Equal(t, expected, publicKey)
+	}
+
+	// NOTE(joel): Without a `.pub` file, the public key is derived from the
+	// private key.
+	require.NoError(t, os.Remove(key+".pub"))
+	publicKey, err := sshPublicKey(key)
+	require.NoError(t, err)
+	assert.Contains(t, expected, publicKey)
+}
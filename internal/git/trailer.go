@@ -0,0 +1,151 @@
+package git
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var trailerRegexp, emptyBreakingRegexp, referenceRegexp *regexp.Regexp
+
+func init() {
+	// NOTE(joel): A footer consists of a word token (or `BREAKING CHANGE`)
+	// followed by either `: ` or ` #`, see
+	// https://www.conventionalcommits.org/en/v1.0.0/#specification
+	trailerRegexp = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z][\w-]*)(?:: | #)(.*)$`)
+	// NOTE(joel): A breaking change footer announces a breaking change even
+	// without a description, e.g. `BREAKING CHANGE:`.
+	emptyBreakingRegexp = regexp.MustCompile(`^(BREAKING[ -]CHANGE):[ \t]*()$`)
+	referenceRegexp = regexp.MustCompile(`#\d+\b`)
+}
+
+// NOTE(joel): Trailers that reference issues, e.g. `Closes #123`.
+var referenceKeys = []string{"Closes", "Fixes", "Resolves", "Refs"}
+
+// Trailer is a git trailer (or Conventional Commits footer) like
+// `Refs: #123`.
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// IsBreaking reports whether the trailer announces a breaking change. Per the
+// spec, the token must be upper-case.
+func (t Trailer) IsBreaking() bool {
+	return t.Key == "BREAKING CHANGE" || t.Key == "BREAKING-CHANGE"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// TrailerValues returns the values of all trailers with the given key. Keys
+// are compared case-insensitively.
+func (c *Commit) TrailerValues(key string) []string {
+	values := make([]string, 0)
+	for _, t := range c.Trailers {
+		if strings.EqualFold(t.Key, key) {
+			values = append(values, t.Value)
+		}
+	}
+	return values
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// References returns the unique issue references (e.g. `#123`) of the
+// `Closes`, `Fixes`, `Resolves` and `Refs` trailers.
+func (c *Commit) References() []string {
+	refs := make([]string, 0)
+	for _, key := range referenceKeys {
+		for _, value := range c.TrailerValues(key) {
+			for _, ref := range referenceRegexp.FindAllString(value, -1) {
+				if !slices.Contains(refs, ref) {
+					refs = append(refs, ref)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// parseTrailers parses the trailers of a commit body. Trailers are the
+// trailing paragraphs of the body that start with a `<token>: <value>` line.
+// Lines that don't start a new trailer continue the value of the previous
+// one, across blank lines until the next trailer starts.
+func parseTrailers(body string) []Trailer {
+	paragraphs := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n")
+
+	// NOTE(joel): Walk backwards to find the first paragraph of the trailer
+	// block. Only a trailing run of trailer paragraphs counts, otherwise a
+	// `Fixes #12 by ...` or `Note: ...` line of the body would be taken for a
+	// trailer. The exception are breaking changes. Their description may span
+	// several paragraphs, so paragraphs that don't start with a trailer
+	// continue a breaking change before them.
+	start := len(paragraphs)
+	pending := false
+	for i := len(paragraphs) - 1; i >= 0; i-- {
+		p := strings.TrimSpace(paragraphs[i])
+		if p == "" {
+			continue
+		}
+		if matchTrailer(strings.SplitN(p, "\n", 2)[0]) == nil {
+			pending = true
+			continue
+		}
+		if pending && !lastTrailer(p).IsBreaking() {
+			break
+		}
+		pending = false
+		start = i
+	}
+
+	var trailers []Trailer
+	for i, p := range paragraphs[start:] {
+		for j, line := range strings.Split(strings.TrimSpace(p), "\n") {
+			if m := matchTrailer(line); m != nil {
+				value := m[2]
+				if strings.HasPrefix(line[len(m[1]):], " #") {
+					value = "#" + value
+				}
+				trailers = append(trailers, Trailer{Key: m[1], Value: strings.TrimSpace(value)})
+				continue
+			}
+			if len(trailers) > 0 {
+				sep := "\n"
+				if i > 0 && j == 0 {
+					sep = "\n\n"
+				}
+				last := &trailers[len(trailers)-1]
+				last.Value = strings.TrimSpace(last.Value + sep + strings.TrimSpace(line))
+			}
+		}
+	}
+	return trailers
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// matchTrailer returns the submatches (key and value) of a trailer line or
+// nil if the line doesn't start a trailer.
+func matchTrailer(line string) []string {
+	if m := trailerRegexp.FindStringSubmatch(line); m != nil {
+		return m
+	}
+	return emptyBreakingRegexp.FindStringSubmatch(line)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// lastTrailer returns the last trailer that starts in the given paragraph.
+func lastTrailer(paragraph string) Trailer {
+	var t Trailer
+	for _, line := range strings.Split(paragraph, "\n") {
+		if m := matchTrailer(line); m != nil {
+			t = Trailer{Key: m[1], Value: m[2]}
+		}
+	}
+	return t
+}
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []Trailer
+	}{
+		{
+			name:     "Empty body",
+			body:     "",
+			expected: nil,
+		},
+		{
+			name:     "Body without trailers",
+			body:     "Some description.\n\nAnother paragraph.",
+			expected: nil,
+		},
+		{
+			name: "Conventional Commits footers",
+			body: "Some description.\n\nBREAKING CHANGE: the config format changed\nRefs: #12\nCloses #34\nCo-authored-by: Jane Doe <jane@example.com>",
+			expected: []Trailer{
+				{Key: "BREAKING CHANGE", Value: "the config format changed"},
+				{Key: "Refs", Value: "#12"},
+				{Key: "Closes", Value: "#34"},
+				{Key: "Co-authored-by", Value: "Jane Doe <jane@example.com>"},
+			},
+		},
+		{
+			name: "Multi-line value",
+			body: "BREAKING-CHANGE: the config format changed\nRename `foo` to `bar`.\r\n\r\nReviewed-by: John",
+			expected: []Trailer{
+				{Key: "BREAKING-CHANGE", Value: "the config format changed\nRename `foo` to `bar`."},
+				{Key: "Reviewed-by", Value: "John"},
+			},
+		},
+		{
+			name: "Footer-like line inside the body",
+			body: "Note: this is part of the body.\n\nThe footer follows.\n\nRefs: #1",
+			expected: []Trailer{
+				{Key: "Refs", Value: "#1"},
+			},
+		},
+		{
+			name:     "Footer-like paragraph inside the body without footer",
+			body:     "Note: the check is skipped.\n\nMore text.",
+			expected: nil,
+		},
+		{
+			name:     "Reference inside the body without footer",
+			body:     "Fixes #12 by adding a check.\n\nMore text.",
+			expected: nil,
+		},
+		{
+			name:     "Trailer followed by a paragraph",
+			body:     "Some description.\n\nBREAKING CHANGE: config file renamed.\nRefs: #12\n\nMore text.",
+			expected: nil,
+		},
+		{
+			name: "Breaking change followed by a paragraph",
+			body: "BREAKING CHANGE: config file renamed.\n\nRename `.release-lit.yml` to `release-lit.yml`.",
+			expected: []Trailer{
+				{Key: "BREAKING CHANGE", Value: "config file renamed.\n\nRename `.release-lit.yml` to `release-lit.yml`."},
+			},
+		},
+		{
+			name: "Breaking change with paragraphs between footers",
+			body: "Some description.\n\nBREAKING CHANGE: config file renamed.\n\nMigrate by hand.\n\nRefs: #12",
+			expected: []Trailer{
+				{Key: "BREAKING CHANGE", Value: "config file renamed.\n\nMigrate by hand."},
+				{Key: "Refs", Value: "#12"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expected, parseTrailers(test.body))
+		})
+	}
+}
+
+func TestTrailerValues(t *testing.T) {
+	c := &Commit{Trailers: []Trailer{
+		{Key: "Refs", Value: "#1"},
+		{Key: "Co-authored-by", Value: "Jane"},
+		{Key: "refs", Value: "#2"},
+	}}
+	assert.Equal(t, []string{"#1", "#2"}, c.TrailerValues("Refs"))
+	assert.Equal(t, []string{}, c.TrailerValues("Closes"))
+}
+
+func TestReferences(t *testing.T) {
+	c := &Commit{Trailers: []Trailer{
+		{Key: "Refs", Value: "#1, #2"},
+		{Key: "Closes", Value: "#3"},
+		{Key: "fixes", Value: "#1"},
+		{Key: "Reviewed-by", Value: "#4"},
+	}}
+	assert.Equal(t, []string{"#3", "#1", "#2"}, c.References())
+	assert.Equal(t, []string{}, (&Commit{}).References())
+}
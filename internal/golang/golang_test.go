@@ -1,8 +1,6 @@
 package golang
 
 import (
-	"os"
-	"path"
 	"testing"
 
 	"github.com/joelvoss/release-lit/internal/semver"
@@ -10,53 +8,26 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func prepareDir(t *testing.T) (string, func()) {
-	tempDir, err := os.MkdirTemp("", "release-me-repo-*")
-	if err != nil {
-		t.Fatalf("Error creating temp directory: %v", err)
-	}
-
-	cleanUpFunc := func() {
-		os.RemoveAll(tempDir)
-	}
-
-	taskfileSh := []byte(`#!/bin/bash
+const taskfileSh = `#!/bin/bash
 
 set -e
 
 NAME="release-lit"
-VERSION="0.1.0"`)
-	err = os.WriteFile(tempDir+"/Taskfile.sh", taskfileSh, 0644)
-	if err != nil {
-		t.Fatalf("Error preparing version file: %v", err)
-	}
-
-	return tempDir, cleanUpFunc
-}
-
-func assertFileContent(t *testing.T, path string, expected string) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		t.Fatalf("Error reading file: %v", err)
-	}
-
-	assert.Equal(t, content, []byte(expected))
-}
+VERSION="0.1.0"`
 
 ////////////////////////////////////////////////////////////////////////////////
 
-func TestUpdateVersion(t *testing.T) {
-	tempDir, cleanUp := prepareDir(t)
-	defer cleanUp()
-
-	v, _ := semver.Parse("1.1.0")
-	err := UpdateVersion(v, tempDir)
+func TestReplaceVersion(t *testing.T) {
+	v, _ := semver.Parse("2.0.0")
+	got := ReplaceVersion(v, []byte(`VERSION="1.0.0"`))
+	assert.Equal(t, `VERSION="2.0.0"`, string(got))
 
-	assert.NoError(t, err)
-	assertFileContent(t, path.Join(tempDir, "Taskfile.sh"), `#!/bin/bash
+	v, _ = semver.Parse("1.1.0")
+	got = ReplaceVersion(v, []byte(taskfileSh))
+	assert.Equal(t, `#!/bin/bash
 
 set -e
 
 NAME="release-lit"
-VERSION="1.1.0"`)
+VERSION="1.1.0"`, string(got))
 }
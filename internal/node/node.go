@@ -1,8 +1,6 @@
 package node
 
 import (
-	"os"
-	"path"
 	"regexp"
 
 	"github.com/joelvoss/release-lit/internal/semver"
@@ -14,24 +12,14 @@ func init() {
 	npmRegexp = regexp.MustCompile(`(?i)"version":\s*".*"`)
 }
 
-func UpdateVersion(v *semver.Version, cwd string) error {
-	f := path.Join(cwd, "./package.json")
+// VersionFile is the path of the version file relative to the project root.
+const VersionFile = "package.json"
 
-	if _, err := os.Stat(f); err != nil {
-		return err
-	}
-
-	content, err := os.ReadFile(f)
-	if err != nil {
-		return err
-	}
+////////////////////////////////////////////////////////////////////////////////
 
+// ReplaceVersion returns a copy of the given version file content with the
+// version field set to v.
+func ReplaceVersion(v *semver.Version, content []byte) []byte {
 	repl := []byte(`"version": "` + v.ToString() + `"`)
-	content = npmRegexp.ReplaceAll(content, repl)
-
-	if err := os.WriteFile(f, content, 0644); err != nil {
-		return err
-	}
-
-	return nil
+	return npmRegexp.ReplaceAll(content, repl)
 }
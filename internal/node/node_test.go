@@ -1,8 +1,6 @@
 package node
 
 import (
-	"os"
-	"path"
 	"testing"
 
 	"github.com/joelvoss/release-lit/internal/semver"
@@ -10,51 +8,24 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func prepareDir(t *testing.T) (string, func()) {
-	tempDir, err := os.MkdirTemp("", "release-me-repo-*")
-	if err != nil {
-		t.Fatalf("Error creating temp directory: %v", err)
-	}
-
-	cleanUpFunc := func() {
-		os.RemoveAll(tempDir)
-	}
-
-	npmVersionFile := []byte(`{
+const npmVersionFile = `{
 "name": "test-repo",
 "version": "1.0.0",
 "description": "Test repository"
-}`)
-	err = os.WriteFile(tempDir+"/package.json", npmVersionFile, 0644)
-	if err != nil {
-		t.Fatalf("Error preparing version file: %v", err)
-	}
-
-	return tempDir, cleanUpFunc
-}
-
-func assertFileContent(t *testing.T, path string, expected string) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		t.Fatalf("Error reading file: %v", err)
-	}
-
-	assert.Equal(t, content, []byte(expected))
-}
+}`
 
 ////////////////////////////////////////////////////////////////////////////////
 
-func TestUpdateVersion(t *testing.T) {
-	tempDir, cleanUp := prepareDir(t)
-	defer cleanUp()
-
-	v, _ := semver.Parse("1.1.0")
-	err := UpdateVersion(v, tempDir)
+func TestReplaceVersion(t *testing.T) {
+	v, _ := semver.Parse("2.0.0")
+	got := ReplaceVersion(v, []byte(`{"version": "1.0.0"}`))
+	assert.Equal(t, `{"version": "2.0.0"}`, string(got))
 
-	assert.NoError(t, err)
-	assertFileContent(t, path.Join(tempDir, "package.json"), `{
+	v, _ = semver.Parse("1.1.0")
+	got = ReplaceVersion(v, []byte(npmVersionFile))
+	assert.Equal(t, `{
 "name": "test-repo",
 "version": "1.1.0",
 "description": "Test repository"
-}`)
+}`, string(got))
 }
@@ -0,0 +1,166 @@
+package publish
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/forge"
+)
+
+// Gitea publishes releases via the Gitea API. Forgejo serves the same API.
+type Gitea struct {
+	// NOTE(joel): Base URL of the REST API, e.g. `https://codeberg.org/api/v1`.
+	APIURL string
+	Owner  string
+	Repo   string
+	Token  string
+	// NOTE(joel): If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+type giteaRelease struct {
+	ID      int64        `json:"id"`
+	HTMLURL string       `json:"html_url"`
+	Assets  []giteaAsset `json:"assets"`
+}
+
+type giteaAsset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NewGitea returns a Gitea (or Forgejo) publisher for the repository of the
+// forge. If apiURL is empty, the API URL of the forge is used.
+func NewGitea(f *forge.Forge, apiURL string, token string) (*Gitea, error) {
+	name := "Gitea"
+	if f.Type == forge.Forgejo {
+		name = "Forgejo"
+	}
+	if token == "" {
+		return nil, missingTokenError(name, f.Type)
+	}
+	owner, repo, ok := strings.Cut(f.Path(), "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("invalid %s repository '%s'", name, f.URL)
+	}
+	return &Gitea{
+		APIURL: strings.TrimSuffix(cmp.Or(apiURL, f.APIURL()), "/"),
+		Owner:  owner,
+		Repo:   repo,
+		Token:  token,
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Publish creates the release of the tag or updates it if it already exists.
+// Assets replace existing assets of the same name. Returns the web URL of the
+// release.
+func (g *Gitea) Publish(r *Release) (string, error) {
+	existing, err := g.getRelease(r.Tag)
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]any{
+		"tag_name":   r.Tag,
+		"name":       r.Name,
+		"body":       r.Notes,
+		"prerelease": r.Prerelease,
+	}
+	if r.Commit != "" {
+		body["target_commitish"] = r.Commit
+	}
+	var rel giteaRelease
+	if existing == nil {
+		err = g.do(http.MethodPost, g.repoURL("/releases"), body, &rel)
+	} else {
+		err = g.do(http.MethodPatch, g.repoURL(fmt.Sprintf("/releases/%d", existing.ID)), body, &rel)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, path := range r.Assets {
+		if err := g.uploadAsset(&rel, path); err != nil {
+			return "", err
+		}
+	}
+	return rel.HTMLURL, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// getRelease returns the release of the tag or nil if there is none.
+func (g *Gitea) getRelease(tag string) (*giteaRelease, error) {
+	var rel giteaRelease
+	err := g.do(http.MethodGet, g.repoURL("/releases/tags/"+url.PathEscape(tag)), nil, &rel)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// uploadAsset uploads the file to the release. An existing asset of the same
+// name is deleted first.
+func (g *Gitea) uploadAsset(rel *giteaRelease, path string) error {
+	name := filepath.Base(path)
+	for _, a := range rel.Assets {
+		if a.Name == name {
+			err := g.do(http.MethodDelete, g.repoURL(fmt.Sprintf("/releases/%d/assets/%d", rel.ID, a.ID)), nil, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	endpoint := g.repoURL(fmt.Sprintf("/releases/%d/assets?name=%s", rel.ID, url.QueryEscape(name)))
+	req, err := newUploadRequest(http.MethodPost, endpoint, "attachment", path)
+	if err != nil {
+		return err
+	}
+	g.authorize(req)
+	return send(g.Client, req, nil)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// do sends an authorized JSON request to the API.
+func (g *Gitea) do(method string, endpoint string, body any, out any) error {
+	req, err := newJSONRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	g.authorize(req)
+	return send(g.Client, req, out)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// authorize sets the authentication header.
+func (g *Gitea) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "token "+g.Token)
+	req.Header.Set("Accept", "application/json")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// repoURL returns the API URL of the given path below the repository.
+func (g *Gitea) repoURL(path string) string {
+	return fmt.Sprintf(
+		"%s/repos/%s/%s%s", g.APIURL, url.PathEscape(g.Owner), url.PathEscape(g.Repo), path,
+	)
+}
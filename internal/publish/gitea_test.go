@@ -0,0 +1,156 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/forge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitea is an in-memory Gitea/Forgejo releases API.
+type fakeGitea struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	releases map[string]map[string]any
+	assets   map[string]string
+	requests []string
+}
+
+func newFakeGitea(t *testing.T) *fakeGitea {
+	f := &fakeGitea{
+		releases: make(map[string]map[string]any),
+		assets:   make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/releases/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		rel, ok := f.releases[r.PathValue("tag")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "The target couldn't be found."}`)
+			return
+		}
+		json.NewEncoder(w).Encode(f.withAssets(rel))
+	})
+	mux.HandleFunc("POST /api/v1/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		var rel map[string]any
+		json.NewDecoder(r.Body).Decode(&rel)
+		rel["id"] = 7
+		rel["html_url"] = fmt.Sprintf("https://codeberg.org/owner/repo/releases/tag/%s", rel["tag_name"])
+		f.releases[rel["tag_name"].(string)] = rel
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(f.withAssets(rel))
+	})
+	mux.HandleFunc("PATCH /api/v1/repos/owner/repo/releases/7", func(w http.ResponseWriter, r *http.Request) {
+		var update map[string]any
+		json.NewDecoder(r.Body).Decode(&update)
+		rel := f.releases[update["tag_name"].(string)]
+		for k, v := range update {
+			rel[k] = v
+		}
+		json.NewEncoder(w).Encode(f.withAssets(rel))
+	})
+	mux.HandleFunc("POST /api/v1/repos/owner/repo/releases/7/assets", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("attachment")
+		require.NoError(t, err)
+		content, _ := io.ReadAll(file)
+		f.assets[r.URL.Query().Get("name")] = string(content)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("DELETE /api/v1/repos/owner/repo/releases/7/assets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.PathValue("id"))
+		delete(f.assets, "app.zip")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "token is required"}`)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGitea) withAssets(rel map[string]any) map[string]any {
+	assets := make([]map[string]any, 0)
+	id := 0
+	for name := range f.assets {
+		id++
+		assets = append(assets, map[string]any{"id": id, "name": name})
+	}
+	copied := map[string]any{"assets": assets}
+	for k, v := range rel {
+		copied[k] = v
+	}
+	return copied
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestNewGitea(t *testing.T) {
+	g, err := NewGitea(&forge.Forge{Type: forge.Forgejo, URL: "https://codeberg.org/owner/repo"}, "", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "https://codeberg.org/api/v1", g.APIURL)
+	assert.Equal(t, "owner", g.Owner)
+	assert.Equal(t, "repo", g.Repo)
+
+	_, err = NewGitea(&forge.Forge{Type: forge.Forgejo, URL: "https://codeberg.org/owner/repo"}, "", "")
+	assert.EqualError(t, err, "No Forgejo token found. Set the FORGEJO_TOKEN environment variable")
+
+	_, err = NewGitea(&forge.Forge{Type: forge.Gitea, URL: "https://gitea.com/owner/repo"}, "", "")
+	assert.EqualError(t, err, "No Gitea token found. Set the GITEA_TOKEN environment variable")
+}
+
+func TestGiteaPublish(t *testing.T) {
+	fake := newFakeGitea(t)
+	asset := writeAsset(t, t.TempDir(), "app.zip", "v1")
+
+	f := &forge.Forge{Type: forge.Forgejo, URL: "https://codeberg.org/owner/repo"}
+	g, err := NewGitea(f, fake.server.URL+"/api/v1", "secret")
+	require.NoError(t, err)
+
+	url, err := g.Publish(&Release{
+		Tag:        "v2.0.0-beta.1",
+		Name:       "v2.0.0-beta.1",
+		Notes:      "- some feature",
+		Prerelease: true,
+		Assets:     []string{asset},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://codeberg.org/owner/repo/releases/tag/v2.0.0-beta.1", url)
+
+	rel := fake.releases["v2.0.0-beta.1"]
+	assert.Equal(t, "- some feature", rel["body"])
+	assert.Equal(t, true, rel["prerelease"])
+	assert.Equal(t, map[string]string{"app.zip": "v1"}, fake.assets)
+
+	// NOTE(joel): Publishing again updates the release and replaces the asset.
+	asset = writeAsset(t, t.TempDir(), "app.zip", "v2")
+	fake.requests = nil
+	_, err = g.Publish(&Release{Tag: "v2.0.0-beta.1", Name: "v2.0.0-beta.1", Notes: "new", Assets: []string{asset}})
+	require.NoError(t, err)
+	assert.Equal(t, "new", fake.releases["v2.0.0-beta.1"]["body"])
+	assert.Equal(t, map[string]string{"app.zip": "v2"}, fake.assets)
+	assert.Equal(t, []string{
+		"GET /api/v1/repos/owner/repo/releases/tags/v2.0.0-beta.1",
+		"PATCH /api/v1/repos/owner/repo/releases/7",
+		"DELETE /api/v1/repos/owner/repo/releases/7/assets/1",
+		"POST /api/v1/repos/owner/repo/releases/7/assets",
+	}, fake.requests)
+}
@@ -0,0 +1,183 @@
+package publish
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/forge"
+)
+
+const githubAPIVersion = "2022-11-28"
+
+// GitHub publishes releases via the GitHub REST API.
+type GitHub struct {
+	// NOTE(joel): Base URL of the REST API, e.g. `https://api.github.com` or
+	// `https://ghe.example.com/api/v3` for GitHub Enterprise.
+	APIURL string
+	Owner  string
+	Repo   string
+	Token  string
+	// NOTE(joel): If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+type githubRelease struct {
+	ID        int64         `json:"id"`
+	HTMLURL   string        `json:"html_url"`
+	UploadURL string        `json:"upload_url"`
+	Assets    []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NewGitHub returns a GitHub publisher for the repository of the forge. If
+// apiURL is empty, the API URL of the forge is used.
+func NewGitHub(f *forge.Forge, apiURL string, token string) (*GitHub, error) {
+	if token == "" {
+		return nil, missingTokenError("GitHub", forge.GitHub)
+	}
+	owner, repo, ok := strings.Cut(f.Path(), "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("invalid GitHub repository '%s'", f.URL)
+	}
+	return &GitHub{
+		APIURL: strings.TrimSuffix(cmp.Or(apiURL, f.APIURL()), "/"),
+		Owner:  owner,
+		Repo:   repo,
+		Token:  token,
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Publish creates the release of the tag or updates it if it already exists.
+// Assets replace existing assets of the same name. Returns the web URL of the
+// release.
+func (g *GitHub) Publish(r *Release) (string, error) {
+	existing, err := g.getRelease(r.Tag)
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]any{
+		"tag_name":   r.Tag,
+		"name":       r.Name,
+		"body":       r.Notes,
+		"prerelease": r.Prerelease,
+	}
+	if r.Commit != "" {
+		body["target_commitish"] = r.Commit
+	}
+	var rel githubRelease
+	if existing == nil {
+		err = g.do(http.MethodPost, g.repoURL("/releases"), body, &rel)
+	} else {
+		err = g.do(http.MethodPatch, g.repoURL(fmt.Sprintf("/releases/%d", existing.ID)), body, &rel)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, path := range r.Assets {
+		if err := g.uploadAsset(&rel, path); err != nil {
+			return "", err
+		}
+	}
+	return rel.HTMLURL, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// getRelease returns the release of the tag or nil if there is none.
+func (g *GitHub) getRelease(tag string) (*githubRelease, error) {
+	var rel githubRelease
+	err := g.do(http.MethodGet, g.repoURL("/releases/tags/"+url.PathEscape(tag)), nil, &rel)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// uploadAsset uploads the file to the release. An existing asset of the same
+// name is deleted first.
+func (g *GitHub) uploadAsset(rel *githubRelease, path string) error {
+	name := filepath.Base(path)
+	for _, a := range rel.Assets {
+		if a.Name == name {
+			err := g.do(http.MethodDelete, g.repoURL(fmt.Sprintf("/releases/assets/%d", a.ID)), nil, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Error reading asset. Reason: '%s'", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("Error reading asset. Reason: '%s'", err)
+	}
+
+	// NOTE(joel): The upload URL is a URI template like
+	// `https://uploads.github.com/repos/o/r/releases/1/assets{?name,label}`.
+	uploadURL, _, _ := strings.Cut(rel.UploadURL, "{")
+	req, err := http.NewRequest(http.MethodPost, uploadURL+"?name="+url.QueryEscape(name), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", cmp.Or(mime.TypeByExtension(filepath.Ext(name)), "application/octet-stream"))
+	g.authorize(req)
+	return send(g.Client, req, nil)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// do sends an authorized JSON request to the API.
+func (g *GitHub) do(method string, endpoint string, body any, out any) error {
+	req, err := newJSONRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	g.authorize(req)
+	return send(g.Client, req, out)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// authorize sets the authentication and API version headers.
+func (g *GitHub) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", githubAPIVersion)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// repoURL returns the API URL of the given path below the repository.
+func (g *GitHub) repoURL(path string) string {
+	return fmt.Sprintf(
+		"%s/repos/%s/%s%s", g.APIURL, url.PathEscape(g.Owner), url.PathEscape(g.Repo), path,
+	)
+}
@@ -0,0 +1,224 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/forge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub is an in-memory GitHub releases API.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	releases map[string]map[string]any
+	assets   map[string]string
+	deleted  []string
+	requests []string
+}
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	f := &fakeGitHub{
+		releases: make(map[string]map[string]any),
+		assets:   make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/releases/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		rel, ok := f.releases[r.PathValue("tag")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+			return
+		}
+		json.NewEncoder(w).Encode(f.withAssets(rel))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		var rel map[string]any
+		json.NewDecoder(r.Body).Decode(&rel)
+		rel["id"] = len(f.releases) + 1
+		rel["html_url"] = fmt.Sprintf("https://github.com/owner/repo/releases/tag/%s", rel["tag_name"])
+		rel["upload_url"] = fmt.Sprintf("%s/uploads/%d/assets{?name,label}", f.server.URL, rel["id"])
+		f.releases[rel["tag_name"].(string)] = rel
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(f.withAssets(rel))
+	})
+	mux.HandleFunc("PATCH /repos/owner/repo/releases/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var update map[string]any
+		json.NewDecoder(r.Body).Decode(&update)
+		rel := f.releases[update["tag_name"].(string)]
+		assert.Equal(t, fmt.Sprint(rel["id"]), r.PathValue("id"))
+		for k, v := range update {
+			rel[k] = v
+		}
+		json.NewEncoder(w).Encode(f.withAssets(rel))
+	})
+	mux.HandleFunc("DELETE /repos/owner/repo/releases/assets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		f.deleted = append(f.deleted, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /uploads/{id}/assets", func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		f.assets[r.URL.Query().Get("name")] = string(content)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "Bad credentials"}`)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGitHub) withAssets(rel map[string]any) map[string]any {
+	assets := make([]map[string]any, 0)
+	id := 100
+	for name := range f.assets {
+		id++
+		assets = append(assets, map[string]any{"id": id, "name": name})
+	}
+	copied := map[string]any{"assets": assets}
+	for k, v := range rel {
+		copied[k] = v
+	}
+	return copied
+}
+
+func writeAsset(t *testing.T, dir string, name string, content string) string {
+	p := path.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatalf("Error writing asset: %v", err)
+	}
+	return p
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestNewGitHub(t *testing.T) {
+	g, err := NewGitHub(&forge.Forge{Type: forge.GitHub, URL: "https://ghe.example.com/owner/repo"}, "", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "https://ghe.example.com/api/v3", g.APIURL)
+	assert.Equal(t, "owner", g.Owner)
+	assert.Equal(t, "repo", g.Repo)
+
+	g, err = NewGitHub(&forge.Forge{Type: forge.GitHub, URL: "https://github.com/owner/repo"}, "http://localhost:1234/", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:1234", g.APIURL)
+
+	_, err = NewGitHub(&forge.Forge{Type: forge.GitHub, URL: "https://github.com/owner/repo"}, "", "")
+	assert.EqualError(t, err, "No GitHub token found. Set the GITHUB_TOKEN environment variable")
+
+	_, err = NewGitHub(&forge.Forge{Type: forge.GitHub, URL: "https://github.com/owner"}, "", "secret")
+	assert.EqualError(t, err, "invalid GitHub repository 'https://github.com/owner'")
+}
+
+func TestGitHubPublish(t *testing.T) {
+	fake := newFakeGitHub(t)
+	dir := t.TempDir()
+	asset := writeAsset(t, dir, "app.tar.gz", "v1")
+
+	g, err := NewGitHub(&forge.Forge{Type: forge.GitHub, URL: "https://github.com/owner/repo"}, fake.server.URL, "secret")
+	require.NoError(t, err)
+
+	url, err := g.Publish(&Release{
+		Tag:        "v2.0.0-rc.1",
+		Commit:     "1234567891234567891234567891234567891234",
+		Name:       "v2.0.0-rc.1",
+		Notes:      "## 2.0.0-rc.1\n\n- some feature",
+		Prerelease: true,
+		Assets:     []string{asset},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/owner/repo/releases/tag/v2.0.0-rc.1", url)
+
+	rel := fake.releases["v2.0.0-rc.1"]
+	assert.Equal(t, "## 2.0.0-rc.1\n\n- some feature", rel["body"])
+	assert.Equal(t, true, rel["prerelease"])
+	assert.Equal(t, "1234567891234567891234567891234567891234", rel["target_commitish"])
+	assert.Equal(t, map[string]string{"app.tar.gz": "v1"}, fake.assets)
+	assert.Equal(t, []string{
+		"GET /repos/owner/repo/releases/tags/v2.0.0-rc.1",
+		"POST /repos/owner/repo/releases",
+		"POST /uploads/1/assets",
+	}, fake.requests)
+}
+
+func TestGitHubPublishUpdate(t *testing.T) {
+	fake := newFakeGitHub(t)
+	dir := t.TempDir()
+	asset := writeAsset(t, dir, "app.tar.gz", "v1")
+
+	g, err := NewGitHub(&forge.Forge{Type: forge.GitHub, URL: "https://github.com/owner/repo"}, fake.server.URL, "secret")
+	require.NoError(t, err)
+
+	_, err = g.Publish(&Release{Tag: "v1.0.0", Name: "v1.0.0", Notes: "old", Assets: []string{asset}})
+	require.NoError(t, err)
+
+	// NOTE(joel): Publishing again updates the release and replaces the asset.
+	writeAsset(t, dir, "app.tar.gz", "v2")
+	fake.requests = nil
+	_, err = g.Publish(&Release{Tag: "v1.0.0", Name: "v1.0.0", Notes: "new", Assets: []string{asset}})
+	require.NoError(t, err)
+
+	assert.Len(t, fake.releases, 1)
+	assert.Equal(t, "new", fake.releases["v1.0.0"]["body"])
+	assert.Equal(t, false, fake.releases["v1.0.0"]["prerelease"])
+	assert.Equal(t, map[string]string{"app.tar.gz": "v2"}, fake.assets)
+	assert.Equal(t, []string{"101"}, fake.deleted)
+	assert.Equal(t, []string{
+		"GET /repos/owner/repo/releases/tags/v1.0.0",
+		"PATCH /repos/owner/repo/releases/1",
+		"DELETE /repos/owner/repo/releases/assets/101",
+		"POST /uploads/1/assets",
+	}, fake.requests)
+}
+
+func TestGitHubPublishError(t *testing.T) {
+	fake := newFakeGitHub(t)
+
+	g, err := NewGitHub(&forge.Forge{Type: forge.GitHub, URL: "https://github.com/owner/repo"}, fake.server.URL, "wrong")
+	require.NoError(t, err)
+
+	_, err = g.Publish(&Release{Tag: "v1.0.0", Name: "v1.0.0"})
+	assert.EqualError(t, err, fmt.Sprintf(
+		"GET %s/repos/owner/repo/releases/tags/v1.0.0 failed with status 401: Bad credentials",
+		fake.server.URL,
+	))
+}
+
+func TestAssets(t *testing.T) {
+	dir := t.TempDir()
+	a := writeAsset(t, dir, "a.zip", "a")
+	b := writeAsset(t, dir, "b.zip", "b")
+	c := writeAsset(t, dir, "c.txt", "c")
+	require.NoError(t, os.Mkdir(path.Join(dir, "d.zip"), 0755))
+
+	assets, err := Assets([]string{path.Join(dir, "*.zip"), c, a})
+	require.NoError(t, err)
+	assert.Equal(t, []string{a, b, c}, assets)
+
+	_, err = Assets([]string{path.Join(dir, "*.exe")})
+	assert.EqualError(t, err, fmt.Sprintf("No files match the asset pattern '%s'", path.Join(dir, "*.exe")))
+
+	_, err = Assets([]string{"["})
+	assert.ErrorContains(t, err, "invalid asset pattern '['")
+}
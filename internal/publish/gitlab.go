@@ -0,0 +1,186 @@
+package publish
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/forge"
+)
+
+// GitLab publishes releases via the GitLab Releases API. Assets are uploaded
+// to the project and linked to the release. GitLab has no pre-release flag,
+// so Release.Prerelease is ignored.
+type GitLab struct {
+	// NOTE(joel): Base URL of the REST API, e.g. `https://gitlab.com/api/v4`.
+	APIURL string
+	// NOTE(joel): Web URL of the project. Uploaded assets are linked below it.
+	WebURL string
+	// NOTE(joel): Full path of the project, e.g. `group/subgroup/repo`.
+	Project string
+	Token   string
+	// NOTE(joel): If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+type gitlabRelease struct {
+	Links struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+	Assets struct {
+		Links []gitlabLink `json:"links"`
+	} `json:"assets"`
+}
+
+type gitlabLink struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type gitlabUpload struct {
+	URL string `json:"url"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NewGitLab returns a GitLab publisher for the project of the forge. If apiURL
+// is empty, the API URL of the forge is used.
+func NewGitLab(f *forge.Forge, apiURL string, token string) (*GitLab, error) {
+	if token == "" {
+		return nil, missingTokenError("GitLab", forge.GitLab)
+	}
+	project := f.Path()
+	if !strings.Contains(project, "/") {
+		return nil, fmt.Errorf("invalid GitLab project '%s'", f.URL)
+	}
+	return &GitLab{
+		APIURL:  strings.TrimSuffix(cmp.Or(apiURL, f.APIURL()), "/"),
+		WebURL:  f.URL,
+		Project: project,
+		Token:   token,
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Publish creates the release of the tag or updates it if it already exists.
+// Asset links replace existing links of the same name. Returns the web URL of
+// the release.
+func (g *GitLab) Publish(r *Release) (string, error) {
+	existing, err := g.getRelease(r.Tag)
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]any{
+		"tag_name":    r.Tag,
+		"name":        r.Name,
+		"description": r.Notes,
+	}
+	var rel gitlabRelease
+	if existing == nil {
+		if r.Commit != "" {
+			body["ref"] = r.Commit
+		}
+		err = g.do(http.MethodPost, g.projectURL("/releases"), body, &rel)
+	} else {
+		err = g.do(http.MethodPut, g.releaseURL(r.Tag, ""), body, &rel)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, path := range r.Assets {
+		if err := g.uploadAsset(&rel, r.Tag, path); err != nil {
+			return "", err
+		}
+	}
+	return rel.Links.Self, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// getRelease returns the release of the tag or nil if there is none.
+func (g *GitLab) getRelease(tag string) (*gitlabRelease, error) {
+	var rel gitlabRelease
+	err := g.do(http.MethodGet, g.releaseURL(tag, ""), nil, &rel)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// uploadAsset uploads the file to the project and links it to the release. An
+// existing link of the same name is deleted first.
+func (g *GitLab) uploadAsset(rel *gitlabRelease, tag string, path string) error {
+	name := filepath.Base(path)
+	for _, l := range rel.Assets.Links {
+		if l.Name == name {
+			err := g.do(http.MethodDelete, g.releaseURL(tag, fmt.Sprintf("/assets/links/%d", l.ID)), nil, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	req, err := newUploadRequest(http.MethodPost, g.projectURL("/uploads"), "file", path)
+	if err != nil {
+		return err
+	}
+	g.authorize(req)
+	var upload gitlabUpload
+	if err := send(g.Client, req, &upload); err != nil {
+		return err
+	}
+
+	// NOTE(joel): The upload URL is relative to the web URL of the project.
+	link := map[string]any{
+		"name":      name,
+		"url":       g.WebURL + upload.URL,
+		"link_type": "other",
+	}
+	return g.do(http.MethodPost, g.releaseURL(tag, "/assets/links"), link, nil)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// do sends an authorized JSON request to the API.
+func (g *GitLab) do(method string, endpoint string, body any, out any) error {
+	req, err := newJSONRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	g.authorize(req)
+	return send(g.Client, req, out)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// authorize sets the authentication header.
+func (g *GitLab) authorize(req *http.Request) {
+	req.Header.Set("PRIVATE-TOKEN", g.Token)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// projectURL returns the API URL of the given path below the project.
+func (g *GitLab) projectURL(path string) string {
+	return fmt.Sprintf("%s/projects/%s%s", g.APIURL, url.PathEscape(g.Project), path)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// releaseURL returns the API URL of the given path below the release.
+func (g *GitLab) releaseURL(tag string, path string) string {
+	return g.projectURL("/releases/" + url.PathEscape(tag) + path)
+}
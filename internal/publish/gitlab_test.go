@@ -0,0 +1,163 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/forge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitLab is an in-memory GitLab releases API.
+type fakeGitLab struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	releases map[string]map[string]any
+	links    map[string]string
+	uploads  map[string]string
+	requests []string
+}
+
+func newFakeGitLab(t *testing.T) *fakeGitLab {
+	f := &fakeGitLab{
+		releases: make(map[string]map[string]any),
+		links:    make(map[string]string),
+		uploads:  make(map[string]string),
+	}
+
+	const project = "/api/v4/projects/group%2Fsub%2Frepo"
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+project+"/releases/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		rel, ok := f.releases[r.PathValue("tag")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "404 Not Found"}`)
+			return
+		}
+		json.NewEncoder(w).Encode(f.withLinks(rel))
+	})
+	mux.HandleFunc("POST "+project+"/releases", func(w http.ResponseWriter, r *http.Request) {
+		var rel map[string]any
+		json.NewDecoder(r.Body).Decode(&rel)
+		rel["_links"] = map[string]any{"self": fmt.Sprintf("https://gitlab.example.com/group/sub/repo/-/releases/%s", rel["tag_name"])}
+		f.releases[rel["tag_name"].(string)] = rel
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(f.withLinks(rel))
+	})
+	mux.HandleFunc("PUT "+project+"/releases/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		var update map[string]any
+		json.NewDecoder(r.Body).Decode(&update)
+		rel := f.releases[r.PathValue("tag")]
+		for k, v := range update {
+			rel[k] = v
+		}
+		json.NewEncoder(w).Encode(f.withLinks(rel))
+	})
+	mux.HandleFunc("POST "+project+"/uploads", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		content, _ := io.ReadAll(file)
+		f.uploads[header.Filename] = string(content)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"url": "/uploads/abc/%s"}`, header.Filename)
+	})
+	mux.HandleFunc("POST "+project+"/releases/{tag}/assets/links", func(w http.ResponseWriter, r *http.Request) {
+		var link map[string]any
+		json.NewDecoder(r.Body).Decode(&link)
+		f.links[link["name"].(string)] = link["url"].(string)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("DELETE "+project+"/releases/{tag}/assets/links/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.PathValue("id"))
+		delete(f.links, "app.tar.gz")
+		fmt.Fprint(w, `{}`)
+	})
+
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.EscapedPath())
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "401 Unauthorized"}`)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGitLab) withLinks(rel map[string]any) map[string]any {
+	links := make([]map[string]any, 0)
+	id := 0
+	for name := range f.links {
+		id++
+		links = append(links, map[string]any{"id": id, "name": name})
+	}
+	copied := map[string]any{"assets": map[string]any{"links": links}}
+	for k, v := range rel {
+		copied[k] = v
+	}
+	return copied
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestNewGitLab(t *testing.T) {
+	g, err := NewGitLab(&forge.Forge{Type: forge.GitLab, URL: "https://gitlab.example.com/group/sub/repo"}, "", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "https://gitlab.example.com/api/v4", g.APIURL)
+	assert.Equal(t, "group/sub/repo", g.Project)
+
+	_, err = NewGitLab(&forge.Forge{Type: forge.GitLab, URL: "https://gitlab.com/group/repo"}, "", "")
+	assert.EqualError(t, err, "No GitLab token found. Set the GITLAB_TOKEN environment variable")
+}
+
+func TestGitLabPublish(t *testing.T) {
+	fake := newFakeGitLab(t)
+	asset := writeAsset(t, t.TempDir(), "app.tar.gz", "v1")
+
+	f := &forge.Forge{Type: forge.GitLab, URL: "https://gitlab.example.com/group/sub/repo"}
+	g, err := NewGitLab(f, fake.server.URL+"/api/v4", "secret")
+	require.NoError(t, err)
+
+	url, err := g.Publish(&Release{
+		Tag:    "v1.0.0",
+		Commit: "1234567891234567891234567891234567891234",
+		Name:   "v1.0.0",
+		Notes:  "- some feature",
+		Assets: []string{asset},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://gitlab.example.com/group/sub/repo/-/releases/v1.0.0", url)
+
+	rel := fake.releases["v1.0.0"]
+	assert.Equal(t, "- some feature", rel["description"])
+	assert.Equal(t, "1234567891234567891234567891234567891234", rel["ref"])
+	assert.Equal(t, map[string]string{"app.tar.gz": "v1"}, fake.uploads)
+	assert.Equal(t, map[string]string{
+		"app.tar.gz": "https://gitlab.example.com/group/sub/repo/uploads/abc/app.tar.gz",
+	}, fake.links)
+
+	// NOTE(joel): Publishing again updates the release and replaces the link.
+	fake.requests = nil
+	_, err = g.Publish(&Release{Tag: "v1.0.0", Name: "v1.0.0", Notes: "new", Assets: []string{asset}})
+	require.NoError(t, err)
+	assert.Equal(t, "new", fake.releases["v1.0.0"]["description"])
+	assert.Equal(t, []string{
+		"GET /api/v4/projects/group%2Fsub%2Frepo/releases/v1.0.0",
+		"PUT /api/v4/projects/group%2Fsub%2Frepo/releases/v1.0.0",
+		"DELETE /api/v4/projects/group%2Fsub%2Frepo/releases/v1.0.0/assets/links/1",
+		"POST /api/v4/projects/group%2Fsub%2Frepo/uploads",
+		"POST /api/v4/projects/group%2Fsub%2Frepo/releases/v1.0.0/assets/links",
+	}, fake.requests)
+}
@@ -0,0 +1,247 @@
+package publish
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/forge"
+)
+
+// Publisher publishes releases on a forge.
+type Publisher interface {
+	// NOTE(joel): Publish creates the release (or updates an existing release
+	// of the same tag) with the release notes and attaches the assets.
+	// Returns the web URL of the release.
+	Publish(r *Release) (string, error)
+}
+
+// NOTE(joel): Environment variables the token of each forge is read from, in
+// order of precedence.
+var tokenEnvVars = map[string][]string{
+	forge.GitHub:  {"GITHUB_TOKEN", "GH_TOKEN"},
+	forge.GitLab:  {"GITLAB_TOKEN"},
+	forge.Gitea:   {"GITEA_TOKEN"},
+	forge.Forgejo: {"FORGEJO_TOKEN", "GITEA_TOKEN"},
+}
+
+// NOTE(joel): Environment variables CI systems set to the API URL of their
+// forge. They are only read for the forge of the same type.
+var apiURLEnvVars = map[string]string{
+	forge.GitHub: "GITHUB_API_URL",
+	forge.GitLab: "CI_API_V4_URL",
+}
+
+// Release is a release to publish on a forge.
+type Release struct {
+	// NOTE(joel): Tag of the release, e.g. `v1.2.0`. The tag must exist on the
+	// remote already.
+	Tag string
+	// NOTE(joel): Sha of the tagged commit. Forges create missing tags on
+	// this commit instead of the default branch.
+	Commit string
+	Name   string
+	// NOTE(joel): Release notes (Markdown).
+	Notes      string
+	Prerelease bool
+	// NOTE(joel): Paths of the files to attach to the release.
+	Assets []string
+}
+
+// APIError is returned if the forge API responds with an error status.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf(
+		"%s %s failed with status %d: %s", e.Method, e.URL, e.StatusCode, e.Message,
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// New returns the publisher of the forge. The token is read from the
+// environment variables of the forge (e.g. `GITLAB_TOKEN`). If apiURL is
+// empty, the API URL set by the CI system of the forge (e.g. `CI_API_V4_URL`)
+// or else the API URL of the forge is used.
+func New(f *forge.Forge, apiURL string) (Publisher, error) {
+	token := tokenFromEnv(f.Type)
+	if apiURL == "" && apiURLEnvVars[f.Type] != "" {
+		apiURL = os.Getenv(apiURLEnvVars[f.Type])
+	}
+	switch f.Type {
+	case forge.GitHub:
+		return NewGitHub(f, apiURL, token)
+	case forge.GitLab:
+		return NewGitLab(f, apiURL, token)
+	case forge.Gitea, forge.Forgejo:
+		return NewGitea(f, apiURL, token)
+	}
+	return nil, fmt.Errorf("Publishing releases is not supported for forge '%s'", f.Type)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// tokenFromEnv returns the first non-empty token environment variable of the
+// forge type.
+func tokenFromEnv(typ string) string {
+	for _, name := range tokenEnvVars[typ] {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// missingTokenError returns the error of a publisher without token.
+func missingTokenError(name string, typ string) error {
+	return fmt.Errorf(
+		"No %s token found. Set the %s environment variable", name, tokenEnvVars[typ][0],
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Assets returns the files matching the given glob patterns (see
+// filepath.Match). Relative patterns are resolved against the current
+// directory. Every pattern must match at least one file.
+func Assets(patterns []string) ([]string, error) {
+	assets := make([]string, 0)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern '%s': %s", pattern, err)
+		}
+		found := false
+		for _, m := range matches {
+			if info, err := os.Stat(m); err != nil || info.IsDir() {
+				continue
+			}
+			found = true
+			if !slices.Contains(assets, m) {
+				assets = append(assets, m)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("No files match the asset pattern '%s'", pattern)
+		}
+	}
+	return assets, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// newJSONRequest returns a request with the JSON encoded body (if not nil).
+func newJSONRequest(method string, endpoint string, body any) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, endpoint, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// newUploadRequest returns a `multipart/form-data` request that uploads the
+// file in the given form field. The file is streamed, not buffered.
+func newUploadRequest(method string, endpoint string, field string, path string) (*http.Request, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading asset. Reason: '%s'", err)
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		defer f.Close()
+		part, err := mw.CreateFormFile(field, filepath.Base(path))
+		if err == nil {
+			_, err = io.Copy(part, f)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequest(method, endpoint, pr)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// send sends the request and decodes the JSON response into out (if not
+// nil). Error statuses are returned as *APIError.
+func send(client *http.Client, req *http.Request, out any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= 300 {
+		return &APIError{
+			Method:     req.Method,
+			URL:        req.URL.Redacted(),
+			StatusCode: res.StatusCode,
+			Message:    errorMessage(body),
+		}
+	}
+	if out == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid response of %s %s: %s", req.Method, req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// errorMessage extracts the error message of an API error response. Falls
+// back to the raw body.
+func errorMessage(body []byte) string {
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Message != "" {
+		return parsed.Message
+	}
+	return strings.TrimSpace(string(body))
+}
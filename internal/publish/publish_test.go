@@ -0,0 +1,43 @@
+package publish
+
+import (
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/forge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "gh")
+	t.Setenv("GITLAB_TOKEN", "gl")
+	t.Setenv("FORGEJO_TOKEN", "")
+	t.Setenv("GITEA_TOKEN", "gt")
+	t.Setenv("GITHUB_API_URL", "")
+	t.Setenv("CI_API_V4_URL", "https://ci.example.com/api/v4")
+
+	p, err := New(&forge.Forge{Type: forge.GitHub, URL: "https://github.com/o/r"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "gh", p.(*GitHub).Token)
+
+	p, err = New(&forge.Forge{Type: forge.GitLab, URL: "https://gitlab.com/g/r"}, "https://gitlab.example.com/api/v4")
+	require.NoError(t, err)
+	assert.Equal(t, "gl", p.(*GitLab).Token)
+	assert.Equal(t, "https://gitlab.example.com/api/v4", p.(*GitLab).APIURL)
+
+	// NOTE(joel): The API URL of the CI system is only used for its forge.
+	p, err = New(&forge.Forge{Type: forge.GitLab, URL: "https://gitlab.com/g/r"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://ci.example.com/api/v4", p.(*GitLab).APIURL)
+
+	// NOTE(joel): Forgejo falls back to the Gitea token.
+	p, err = New(&forge.Forge{Type: forge.Forgejo, URL: "https://codeberg.org/o/r"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "gt", p.(*Gitea).Token)
+	assert.Equal(t, "https://codeberg.org/api/v1", p.(*Gitea).APIURL)
+
+	_, err = New(&forge.Forge{Type: forge.Bitbucket, URL: "https://bitbucket.org/o/r"}, "")
+	assert.EqualError(t, err, "Publishing releases is not supported for forge 'bitbucket'")
+}
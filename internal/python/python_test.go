@@ -1,8 +1,6 @@
 package python
 
 import (
-	"os"
-	"path"
 	"testing"
 
 	"github.com/joelvoss/release-lit/internal/semver"
@@ -10,49 +8,22 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func prepareDir(t *testing.T) (string, func()) {
-	tempDir, err := os.MkdirTemp("", "release-me-repo-*")
-	if err != nil {
-		t.Fatalf("Error creating temp directory: %v", err)
-	}
-
-	cleanUpFunc := func() {
-		os.RemoveAll(tempDir)
-	}
-
-	pyprojectToml := []byte(`[project]
+const pyprojectToml = `[project]
 name = "test-repo"
 version = "1.0.0"
-description = "Test repository"`)
-	err = os.WriteFile(tempDir+"/pyproject.toml", pyprojectToml, 0644)
-	if err != nil {
-		t.Fatalf("Error preparing version file: %v", err)
-	}
-
-	return tempDir, cleanUpFunc
-}
-
-func assertFileContent(t *testing.T, path string, expected string) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		t.Fatalf("Error reading file: %v", err)
-	}
-
-	assert.Equal(t, content, []byte(expected))
-}
+description = "Test repository"`
 
 ////////////////////////////////////////////////////////////////////////////////
 
-func TestUpdateVersion(t *testing.T) {
-	tempDir, cleanUp := prepareDir(t)
-	defer cleanUp()
-
-	v, _ := semver.Parse("1.1.0")
-	err := UpdateVersion(v, tempDir)
+func TestReplaceVersion(t *testing.T) {
+	v, _ := semver.Parse("2.0.0")
+	got := ReplaceVersion(v, []byte(`version = "1.0.0"`))
+	assert.Equal(t, `version = "2.0.0"`, string(got))
 
-	assert.NoError(t, err)
-	assertFileContent(t, path.Join(tempDir, "pyproject.toml"), `[project]
+	v, _ = semver.Parse("1.1.0")
+	got = ReplaceVersion(v, []byte(pyprojectToml))
+	assert.Equal(t, `[project]
 name = "test-repo"
 version = "1.1.0"
-description = "Test repository"`)
+description = "Test repository"`, string(got))
 }
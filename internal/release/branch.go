@@ -0,0 +1,79 @@
+package release
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/joelvoss/release-lit/internal/semver"
+)
+
+var maintenanceBranchRegexp *regexp.Regexp
+
+func init() {
+	// NOTE(joel): Matches maintenance branches like `1.x`, `1.2.x` or
+	// `release/1.x`.
+	maintenanceBranchRegexp = regexp.MustCompile(`(?:^|/)(\d+(?:\.\d+)?\.x)$`)
+}
+
+// Branch maps a release branch to a release channel.
+type Branch struct {
+	// NOTE(joel): Name of the branch. May be a glob pattern like `release/*`.
+	Name string
+	// NOTE(joel): Pre-release identifier of the channel, e.g. `next`. Empty
+	// for stable releases.
+	Prerelease string
+	// NOTE(joel): Allowed version range, e.g. `1.x` or `>=1.2.0 <2.0.0`. If
+	// empty and the name of the current branch ends with a range (like `1.x`
+	// or `release/1.x`), that range is used. Otherwise all versions are
+	// allowed.
+	Range string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// MatchBranch returns the first branch rule matching the given branch name or
+// nil if none matches.
+func MatchBranch(branches []Branch, name string) *Branch {
+	for i, b := range branches {
+		if b.Name == name {
+			return &branches[i]
+		}
+		if ok, err := path.Match(b.Name, name); err == nil && ok {
+			return &branches[i]
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// AllowedRange returns the version range releases from the given branch must
+// satisfy. The branch is the current branch matching the rule, which differs
+// from the name of the rule for glob patterns like `release/*`.
+func (b *Branch) AllowedRange(branch string) string {
+	if b.Range != "" {
+		return b.Range
+	}
+	if m := maintenanceBranchRegexp.FindStringSubmatch(branch); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Allows reports whether the given version is within the allowed range of
+// releases from the given branch (see AllowedRange).
+func (b *Branch) Allows(branch string, v *semver.Version) (bool, error) {
+	rng := b.AllowedRange(branch)
+	if rng == "" {
+		return true, nil
+	}
+
+	c, err := semver.ParseConstraint(rng)
+	if err != nil {
+		return false, fmt.Errorf("invalid version range '%s' of branch '%s'", rng, b.Name)
+	}
+	return c.Check(v), nil
+}
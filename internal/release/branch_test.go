@@ -0,0 +1,68 @@
+package release
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchBranch(t *testing.T) {
+	branches := []Branch{
+		{Name: "main"},
+		{Name: "next", Prerelease: "next"},
+		{Name: "release/*", Range: "2.x"},
+		{Name: "1.x"},
+	}
+
+	assert.Equal(t, &branches[0], MatchBranch(branches, "main"))
+	assert.Equal(t, &branches[1], MatchBranch(branches, "next"))
+	assert.Equal(t, &branches[2], MatchBranch(branches, "release/2.1"))
+	assert.Equal(t, &branches[3], MatchBranch(branches, "1.x"))
+	assert.Nil(t, MatchBranch(branches, "feature/foo"))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestBranchAllows(t *testing.T) {
+	tests := []struct {
+		branch Branch
+		// NOTE(joel): Current branch. Defaults to the name of the rule.
+		current  string
+		version  string
+		expected bool
+	}{
+		{Branch{Name: "main"}, "", "5.0.0", true},
+		{Branch{Name: "1.x"}, "", "1.9.3", true},
+		{Branch{Name: "1.x"}, "", "2.0.0", false},
+		{Branch{Name: "1.2.x"}, "", "1.2.9", true},
+		{Branch{Name: "1.2.x"}, "", "1.3.0", false},
+		{Branch{Name: "*.x"}, "2.x", "2.4.0", true},
+		{Branch{Name: "*.x"}, "2.x", "3.0.0", false},
+		{Branch{Name: "release/*"}, "release/1.x", "2.0.0", false},
+		{Branch{Name: "release/*"}, "release/next", "2.0.0", true},
+		{Branch{Name: "maintenance", Range: "v3.x"}, "", "3.1.0-rc.1", true},
+		{Branch{Name: "maintenance", Range: "3.x"}, "", "4.0.0-rc.1", false},
+		{Branch{Name: "legacy", Range: ">=1.2.0 <1.5.0"}, "", "1.4.9", true},
+		{Branch{Name: "legacy", Range: ">=1.2.0 <1.5.0"}, "", "1.5.0", false},
+	}
+
+	for _, test := range tests {
+		current := cmp.Or(test.current, test.branch.Name)
+		t.Run(current+"@"+test.version, func(t *testing.T) {
+			t.Parallel()
+
+			v, _ := semver.Parse(test.version)
+			got, err := test.branch.Allows(current, v)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+
+	v, _ := semver.Parse("1.0.0")
+	_, err := (&Branch{Name: "main", Range: "1.y"}).Allows("main", v)
+	assert.EqualError(t, err, "invalid version range '1.y' of branch 'main'")
+}
@@ -0,0 +1,84 @@
+package release
+
+import (
+	"errors"
+
+	"github.com/joelvoss/release-lit/internal/changelog"
+	"github.com/joelvoss/release-lit/internal/git"
+)
+
+// Rebuild regenerates the whole changelog from the git history. Every tag
+// gets a section with the commits since the previous tag (the previous stable
+// tag for stable versions), dated with the date of the tag. Commits after the
+// latest tag are not included. The returned file change is not written to
+// disk.
+func Rebuild(opts *Opts) (*File, error) {
+	root, err := git.GetRoot(&git.GitOpts{RootDir: opts.RootDir})
+	if err != nil {
+		return nil, err
+	}
+	gitOpts := &git.GitOpts{RootDir: root}
+
+	tags, err := git.GetTags(gitOpts)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, errors.New("No release tags found. Nothing to rebuild")
+	}
+
+	f, err := detectForge(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	changelogOpts, err := newChangelogOpts(root, f, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE(joel): Tags are sorted in descending order. Render from the oldest
+	// tag on, prepending every section like consecutive releases would.
+	var content []byte
+	for i := len(tags) - 1; i >= 0; i-- {
+		tag := tags[i]
+
+		// NOTE(joel): Same as for a new release, a stable version covers all
+		// commits since the previous stable tag, a pre-release those since the
+		// tag right before it. Release commits are left out.
+		previous := latestStable(tags[i+1:])
+		if tag.Prerelease() != "" && i < len(tags)-1 {
+			previous = tags[i+1]
+		}
+		previousName := ""
+		if previous != nil {
+			previousName = previous.Original
+		}
+		commits, err := git.GetCommitsBetween(previousName, tag.Original, gitOpts)
+		if err != nil {
+			return nil, err
+		}
+		commits = withoutReleases(commits, tags, opts.Git)
+		date, err := git.GetTagDate(tag.Original, gitOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		changelogOpts.PreviousVersion = previous
+		changelogOpts.Tag = tag.Original
+		changelogOpts.Date = date
+
+		rendered, err := changelog.Render(commits, tag, changelogOpts)
+		if err != nil {
+			return nil, err
+		}
+		content, _ = changelog.Merge(rendered, content, tag, changelogOpts)
+	}
+
+	changelogPath := resolve(root, opts.ChangelogPath)
+	oldChangelog, err := readOptional(changelogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{Path: changelogPath, Old: oldChangelog, New: content}, nil
+}
@@ -0,0 +1,128 @@
+package release
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitAt(t *testing.T, dir string, date string, msg string) {
+	cmd := exec.Command("git", "commit", "--allow-empty", "-m", msg)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Error creating commit: %v (%s)", err, output)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestRebuild(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "CHANGELOG.md"), "# Changelog\n\nBroken by hand\n")
+	commitAt(t, cwd, "2024-01-01T12:00:00Z", "feat: initial feature")
+	runGit(t, cwd, "tag", "v1.0.0")
+	commitAt(t, cwd, "2024-02-01T12:00:00Z", "fix: fix bug")
+	commitAt(t, cwd, "2024-02-02T12:00:00Z", "docs: update readme")
+	runGit(t, cwd, "tag", "v1.0.1")
+	commitAt(t, cwd, "2024-03-01T12:00:00Z", "feat!: breaking change")
+	runGit(t, cwd, "tag", "v2.0.0-rc.1")
+	commitAt(t, cwd, "2024-03-05T12:00:00Z", "feat: unreleased feature")
+
+	f, err := Rebuild(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md"})
+	require.NoError(t, err)
+
+	assert.Equal(t, path.Join(cwd, "CHANGELOG.md"), f.Path)
+	assert.Equal(t, "# Changelog\n\nBroken by hand\n", string(f.Old))
+	assert.Regexp(t, `^# Changelog
+
+## 2\.0\.0-rc\.1 - 2024-03-01
+
+### BREAKING CHANGES
+- feat: breaking change \([0-9a-f]{7}\)
+
+## 1\.0\.1 - 2024-02-02
+
+### Bug Fixes
+- fix bug \([0-9a-f]{7}\)
+
+### Miscellaneous
+- docs: update readme \([0-9a-f]{7}\)
+
+## 1\.0\.0 - 2024-01-01
+
+### Features
+- initial feature \([0-9a-f]{7}\)
+$`, string(f.New))
+
+	// NOTE(joel): Rebuilding must not touch the repository.
+	content, err := os.ReadFile(path.Join(cwd, "CHANGELOG.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Changelog\n\nBroken by hand\n", string(content))
+}
+
+func TestRebuildReleases(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "0.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial feature")
+
+	// NOTE(joel): Tags created by releases point at their release commits.
+	release := func(prerelease string) {
+		plan, err := NewPlan(&Opts{
+			RootDir:       cwd,
+			ChangelogPath: "./CHANGELOG.md",
+			ProjectType:   "node",
+			Prerelease:    prerelease,
+		})
+		require.NoError(t, err)
+		require.NoError(t, plan.Apply())
+	}
+	release("")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+	release("rc")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "fix: fix bug")
+	release("")
+
+	f, err := Rebuild(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(f.New), "release:")
+	assert.Regexp(t, `^# Changelog
+
+## 1\.1\.0 - \d{4}-\d{2}-\d{2}
+
+### Features
+- add feature \([0-9a-f]{7}\)
+
+### Bug Fixes
+- fix bug \([0-9a-f]{7}\)
+
+## 1\.1\.0-rc\.1 - \d{4}-\d{2}-\d{2}
+
+### Features
+- add feature \([0-9a-f]{7}\)
+
+## 1\.0\.0 - \d{4}-\d{2}-\d{2}
+
+### Features
+- initial feature \([0-9a-f]{7}\)
+$`, string(f.New))
+}
+
+func TestRebuildNoTags(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: initial feature")
+
+	_, err := Rebuild(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md"})
+	assert.EqualError(t, err, "No release tags found. Nothing to rebuild")
+}
@@ -0,0 +1,704 @@
+package release
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/joelvoss/release-lit/internal/changelog"
+	"github.com/joelvoss/release-lit/internal/check"
+	"github.com/joelvoss/release-lit/internal/diff"
+	"github.com/joelvoss/release-lit/internal/forge"
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/golang"
+	"github.com/joelvoss/release-lit/internal/node"
+	"github.com/joelvoss/release-lit/internal/python"
+	"github.com/joelvoss/release-lit/internal/semver"
+)
+
+type Opts struct {
+	// NOTE(joel): Root directory of the git repository. If empty, the root is
+	// determined from the current working directory.
+	RootDir string
+	// NOTE(joel): Path of the changelog file. Relative paths are resolved
+	// against the git root.
+	ChangelogPath string
+	// NOTE(joel): Project type (node, python, go).
+	ProjectType string
+	// NOTE(joel): Changelog format (default, keepachangelog).
+	ChangelogFormat string
+	// NOTE(joel): Path of a custom changelog template. Relative paths are
+	// resolved against the git root. If empty, the default template is used.
+	TemplatePath string
+	// NOTE(joel): Path of the template of the standalone release notes (see
+	// Plan.ReleaseNotes). Relative paths are resolved against the git root. If
+	// empty, the changelog template is used.
+	ReleaseNotesTemplatePath string
+	// NOTE(joel): Forge type (github, gitlab, gitea, forgejo, bitbucket). If
+	// empty, the forge is detected from the `origin` remote.
+	Forge string
+	// NOTE(joel): Web URL of the repository. If empty, it is derived from the
+	// `origin` remote.
+	RepoURL string
+	// NOTE(joel): Base URL of the REST API of the forge, used to publish
+	// releases. If empty, it is derived from the repository URL.
+	ForgeAPIURL string
+	// NOTE(joel): Issue URL with `%s` as placeholder for the issue number. If
+	// empty, the URL of the forge is used.
+	IssueURL string
+	// NOTE(joel): Commit URL with `%s` as placeholder for the sha. If empty,
+	// the URL of the forge is used.
+	CommitURL string
+	// NOTE(joel): Compare URL with `%s` placeholders for the previous and the
+	// new tag. If empty, the URL of the forge is used.
+	CompareURL string
+	// NOTE(joel): Add a "Contributors" section to the changelog.
+	Contributors bool
+	// NOTE(joel): Rules that map commit types to release types. If nil, the
+	// default rules are used.
+	Rules git.Rules
+	// NOTE(joel): Pre-release identifier (e.g. `rc`). If set, a pre-release
+	// with an automatically incremented counter is created.
+	Prerelease string
+	// NOTE(joel): Version range the next version must satisfy, e.g.
+	// `>=2.0.0 <3.0.0`. If empty, all versions are allowed.
+	VersionRange string
+	// NOTE(joel): Highest allowed version, e.g. `2.x` or `2.4.0`.
+	MaxVersion string
+	// NOTE(joel): Release branch rules. If set, releases can only be created
+	// from matching branches and must satisfy their channel and version range.
+	Branches []Branch
+	// NOTE(joel): Allow releases from a working tree with uncommitted
+	// changes. Only the files changed by the release are committed anyway.
+	AllowDirty bool
+	// NOTE(joel): Names of the pre-flight checks to skip (see check.Names).
+	SkipChecks []string
+	// NOTE(joel): Message and identity of the release commit + tag.
+	Git *git.ReleaseOpts
+	// NOTE(joel): Remote the release branch + tag are pushed to. If empty,
+	// nothing is pushed.
+	Remote string
+}
+
+// File is a single file change of a release plan.
+type File struct {
+	Path string
+	Old  []byte
+	New  []byte
+}
+
+// Next describes the next release as computed from the tags and commits of
+// the repository.
+type Next struct {
+	Root string
+	Tags []*semver.Version
+	// NOTE(joel): Latest stable (non pre-release) tag. Nil if there is none.
+	Stable *semver.Version
+	// NOTE(joel): Tag the new release follows, i.e. the latest stable tag or,
+	// for pre-releases, the tag with the next lower precedence. The changelog
+	// covers all commits since this tag.
+	Previous *semver.Version
+	// NOTE(joel): Commits since the previous tag.
+	Commits     []*git.Commit
+	ReleaseType int
+	Version     *semver.Version
+	// NOTE(joel): Branch rule matching the current branch. Nil if no branch
+	// rules are configured.
+	Branch *Branch
+}
+
+// Plan holds everything a release would do, computed in memory without
+// touching the repository.
+type Plan struct {
+	Next
+	Changelog []byte
+	Files     []*File
+	Git       *git.ReleaseOpts
+	// NOTE(joel): Forge hosting the repository. Nil if unknown.
+	Forge *forge.Forge
+	// NOTE(joel): Machine-readable release notes built from the same data as
+	// the changelog section.
+	Notes *changelog.Notes
+	// NOTE(joel): Section of the new release only, e.g. for the body of a
+	// forge release. Rendered with the release notes template if configured,
+	// same as Changelog otherwise.
+	ReleaseNotes []byte
+	// NOTE(joel): Remote the release is pushed to. Empty if it isn't pushed.
+	Remote string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NextVersion computes the next version for the repository described by opts.
+func NextVersion(opts *Opts) (*Next, error) {
+	// NOTE(joel): Get git root. This conveniently also checks if the current
+	// directory is a git repository.
+	root, err := git.GetRoot(&git.GitOpts{RootDir: opts.RootDir})
+	if err != nil {
+		return nil, err
+	}
+	gitOpts := &git.GitOpts{RootDir: root}
+
+	// NOTE(joel): Get all tags sorted by version in descending order
+	tags, err := git.GetTags(gitOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Next{
+		Root:   root,
+		Tags:   tags,
+		Stable: latestStable(tags),
+	}
+
+	// NOTE(joel): If branch rules are configured, the current branch must
+	// match one of them. Its channel is used unless a pre-release identifier
+	// is given explicitly.
+	prerelease := opts.Prerelease
+	branch := ""
+	if len(opts.Branches) != 0 {
+		branch, err = git.GetBranch(gitOpts)
+		if err != nil {
+			return nil, err
+		}
+		if branch == "" {
+			return nil, errors.New("HEAD is detached. Releases can only be created from a release branch")
+		}
+		n.Branch = MatchBranch(opts.Branches, branch)
+		if n.Branch == nil {
+			return nil, fmt.Errorf("Branch '%s' is not configured as a release branch", branch)
+		}
+		if prerelease == "" {
+			prerelease = n.Branch.Prerelease
+		}
+	}
+
+	// NOTE(joel): Get commits since the latest stable tag. If there are no
+	// tags, we get all commits (for the changelog). Pre-release tags are
+	// ignored here, so that graduating a pre-release (e.g. `2.0.0-rc.3` to
+	// `2.0.0`) covers all commits of its pre-releases, except for their
+	// release commits.
+	commits, err := commitsSince(n.Stable, gitOpts)
+	if err != nil {
+		return nil, err
+	}
+	commits = withoutReleases(commits, tags, opts.Git)
+	n.Previous = n.Stable
+	n.Commits = commits
+
+	// NOTE(joel): Get next release type based on commits since the latest
+	// stable tag (or all commits if there are no tags).
+	n.ReleaseType = git.GetNextReleaseType(commits, opts.Rules)
+
+	// NOTE(joel): Define new version based on release type and the latest
+	// stable tagged version. If there are no stable tags, we start with version
+	// "1.0.0" regardless of the release type.
+	if n.Stable == nil {
+		n.Version, err = semver.Parse("1.0.0")
+	} else {
+		// NOTE(joel): We pass the latest tag by value to the `Bump` function
+		// to avoid modifying the original tag.
+		n.Version, err = semver.Bump(*n.Stable, n.ReleaseType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE(joel): For pre-releases, the version computed above is the base
+	// version that gets a pre-release counter, e.g. `2.0.0-rc.2`. The changelog
+	// only covers the commits since the previous pre-release. If no release is
+	// due, the version stays at the latest stable tag. A counter would create a
+	// pre-release of it, e.g. `1.0.0-rc.1`, that sorts below `1.0.0`.
+	if prerelease != "" && n.IsDue() {
+		n.Version, err = semver.NextPrerelease(*n.Version, prerelease, tags)
+		if err != nil {
+			return nil, err
+		}
+		if prev := previousTag(n.Version, tags); prev != nil && prev != n.Stable {
+			n.Previous = prev
+			n.Commits, err = commitsSince(prev, gitOpts)
+			if err != nil {
+				return nil, err
+			}
+
+			// NOTE(joel): The base version is derived from all commits since the
+			// latest stable tag. Whether another pre-release of the same base is
+			// due depends on the commits since the previous one only, e.g. a lone
+			// `chore` commit after `1.1.0-rc.1` doesn't warrant `1.1.0-rc.2`.
+			if prev.Prerelease() != "" && prev.SameCore(n.Version) &&
+				git.GetNextReleaseType(n.Commits, opts.Rules) == semver.ReleaseTypeNone {
+				n.ReleaseType = semver.ReleaseTypeNone
+				n.Version = prev
+			}
+		}
+	}
+
+	// NOTE(joel): Refuse releases that escape the allowed version range of the
+	// branch, e.g. a breaking change on a `1.x` maintenance branch.
+	if n.Branch != nil {
+		ok, err := n.Branch.Allows(branch, n.Version)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf(
+				"Version '%s' is outside of the allowed range '%s' of branch '%s'",
+				n.Version.ToString(), n.Branch.AllowedRange(branch), branch,
+			)
+		}
+	}
+
+	// NOTE(joel): Refuse releases that don't satisfy the configured range.
+	if err := checkRange(n.Version, opts.VersionRange, opts.MaxVersion); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkRange checks that v satisfies the version range and does not exceed
+// the max version (if given).
+func checkRange(v *semver.Version, versionRange string, maxVersion string) error {
+	constraints := make([]string, 0, 2)
+	if versionRange != "" {
+		constraints = append(constraints, versionRange)
+	}
+	if maxVersion != "" {
+		constraints = append(constraints, "<="+maxVersion)
+	}
+
+	for _, constraint := range constraints {
+		c, err := semver.ParseConstraint(constraint)
+		if err != nil {
+			return err
+		}
+		if !c.Check(v) {
+			return fmt.Errorf(
+				"Version '%s' does not satisfy the version range '%s'",
+				v.ToString(), constraint,
+			)
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// LatestTag returns the tag with the highest version that satisfies the given
+// version range, e.g. `~1.4`, or nil if none does.
+func LatestTag(opts *Opts, versionRange string) (*semver.Version, error) {
+	c, err := semver.ParseConstraint(versionRange)
+	if err != nil {
+		return nil, err
+	}
+	root, err := git.GetRoot(&git.GitOpts{RootDir: opts.RootDir})
+	if err != nil {
+		return nil, err
+	}
+	tags, err := git.GetTags(&git.GitOpts{RootDir: root})
+	if err != nil {
+		return nil, err
+	}
+	return c.Latest(tags), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// IsDue reports whether a new release is due, i.e. if there are no tags yet or
+// the commits since the previous tag warrant a version bump.
+func (n *Next) IsDue() bool {
+	return n.Previous == nil || n.ReleaseType != semver.ReleaseTypeNone
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NewPlan computes the release plan for the repository described by opts. If
+// no release is due (see IsDue), the plan has no files.
+func NewPlan(opts *Opts) (*Plan, error) {
+	// NOTE(joel): Run the pre-flight checks before anything is computed. Among
+	// others, uncommitted changes would end up in a release that doesn't
+	// contain them (or, if they touch the changelog or version file, in the
+	// release commit), so the working tree must be clean. The checks of the
+	// release tag run once the next version is known. All failures are
+	// reported together.
+	skip := slices.Clone(opts.SkipChecks)
+	if opts.AllowDirty {
+		skip = append(skip, check.Clean)
+	}
+	branches := make([]string, 0, len(opts.Branches))
+	for _, b := range opts.Branches {
+		branches = append(branches, b.Name)
+	}
+	stateErr := check.Run(&check.Opts{
+		RootDir:  opts.RootDir,
+		Branches: branches,
+		Skip:     slices.Concat(skip, check.TagChecks),
+	})
+
+	// NOTE(joel): The version can't be computed e.g. from an unknown branch,
+	// which the failed checks report already.
+	next, err := NextVersion(opts)
+	if err != nil {
+		if stateErr != nil {
+			return nil, stateErr
+		}
+		return nil, err
+	}
+	p := &Plan{Next: *next, Git: opts.Git, Remote: opts.Remote}
+
+	// NOTE(joel): If no release is due, there is no tag to check and nothing
+	// to render. The plan only holds the next version.
+	if !p.IsDue() {
+		if stateErr != nil {
+			return nil, stateErr
+		}
+		return p, nil
+	}
+
+	tagErr := check.Run(&check.Opts{
+		RootDir: p.Root,
+		Tag:     p.Tag(),
+		Skip:    slices.Concat(skip, check.StateChecks),
+	})
+	if err := check.Join(stateErr, tagErr); err != nil {
+		return nil, err
+	}
+
+	p.Forge, err = detectForge(p.Root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE(joel): Render the changelog and prepend it to the current one.
+	changelogOpts, err := newChangelogOpts(p.Root, p.Forge, opts)
+	if err != nil {
+		return nil, err
+	}
+	changelogOpts.PreviousVersion = p.Previous
+	rendered, err := changelog.Render(p.Commits, p.Version, changelogOpts)
+	if err != nil {
+		return nil, err
+	}
+	p.Notes = changelog.NewNotes(p.Commits, p.Version, p.ReleaseType, changelogOpts)
+
+	changelogPath := resolve(p.Root, opts.ChangelogPath)
+	oldChangelog, err := readOptional(changelogPath)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE(joel): A section of the same version is left over from a failed
+	// release and is replaced. If the version is already tagged, the section
+	// belongs to a finished release and is never replaced. The `tag` check
+	// refuses such a release already, unless it is skipped.
+	newChangelog, replaced := changelog.Merge(rendered, oldChangelog, p.Version, changelogOpts)
+	if replaced {
+		tag := p.Tag()
+		tagged, err := git.TagExists(tag, &git.GitOpts{RootDir: p.Root})
+		if err != nil {
+			return nil, err
+		}
+		if tagged {
+			return nil, fmt.Errorf(
+				"Changelog already contains a section for the released version '%s' (tag '%s')",
+				p.Version.ToString(), tag,
+			)
+		}
+	}
+	p.Files = append(p.Files, &File{
+		Path: changelogPath,
+		Old:  oldChangelog,
+		New:  newChangelog,
+	})
+
+	// NOTE(joel): The section is taken from the merged changelog, so that it
+	// contains the hand-written entries moved out of `[Unreleased]` (Keep a
+	// Changelog format).
+	p.Changelog = changelog.SectionContent(newChangelog, p.Version)
+	if p.Changelog == nil {
+		p.Changelog = changelog.StripHeader(rendered)
+	}
+
+	p.ReleaseNotes = p.Changelog
+	if opts.ReleaseNotesTemplatePath != "" {
+		notesOpts := *changelogOpts
+		notesOpts.Template, err = readTemplate(p.Root, opts.ReleaseNotesTemplatePath)
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := changelog.Render(p.Commits, p.Version, &notesOpts)
+		if err != nil {
+			return nil, err
+		}
+		p.ReleaseNotes = changelog.StripHeader(rendered)
+	}
+
+	// NOTE(joel): Update version file based on project type.
+	versionFile, replace, err := versionFileFor(opts.ProjectType)
+	if err != nil {
+		return nil, err
+	}
+	versionFilePath := path.Join(p.Root, versionFile)
+	oldVersionFile, err := os.ReadFile(versionFilePath)
+	if err != nil {
+		return nil, err
+	}
+	p.Files = append(p.Files, &File{
+		Path: versionFilePath,
+		Old:  oldVersionFile,
+		New:  replace(p.Version, oldVersionFile),
+	})
+
+	return p, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Tag returns the name of the tag of the new release, e.g. `v1.2.0`.
+func (p *Plan) Tag() string {
+	return "v" + p.Version.ToString()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Apply writes all file changes of the plan to disk, creates the release
+// commit + tag and pushes them (if a remote is set). If any step fails, all
+// changes made so far are rolled back. The push is atomic, so a failed push
+// leaves the remote untouched.
+func (p *Plan) Apply() (err error) {
+	tx := newTransaction(p.Root)
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rbErr := tx.rollback(); rbErr != nil {
+			msg := fmt.Sprintf("Error rolling back release. Reason: '%s'\n", rbErr)
+			err = errors.Join(err, errors.New(msg))
+		}
+	}()
+
+	files := make([]string, 0, len(p.Files))
+	for _, f := range p.Files {
+		if err := tx.writeFile(f.Path, f.New); err != nil {
+			return err
+		}
+		files = append(files, f.Path)
+	}
+
+	if err := tx.commit(p.Version, files, p.Git); err != nil {
+		return err
+	}
+	if err := tx.tagRelease(p.Version, p.Git); err != nil {
+		return err
+	}
+
+	if p.Remote == "" {
+		return nil
+	}
+	gitOpts := &git.GitOpts{RootDir: p.Root}
+	branch, err := git.GetBranch(gitOpts)
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		return errors.New("HEAD is detached. Releases can only be pushed from a branch")
+	}
+	return push(p.Remote, branch, p.Tag(), gitOpts)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Diff returns a unified diff of every file the plan would change.
+func (p *Plan) Diff() string {
+	var b strings.Builder
+	for _, f := range p.Files {
+		b.WriteString(f.Diff(p.Root))
+	}
+	return b.String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Diff returns a unified diff of the file change. Paths are shown relative to
+// the given root.
+func (f *File) Diff(root string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(f.Path, root), "/")
+	fromName := "a/" + name
+	// NOTE(joel): Files that don't exist yet are diffed against /dev/null.
+	if f.Old == nil {
+		fromName = "/dev/null"
+	}
+	return diff.Unified(fromName, "b/"+name, f.Old, f.New)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// newChangelogOpts returns the changelog options for the given release
+// options. URLs that aren't configured explicitly are taken from the forge.
+func newChangelogOpts(root string, f *forge.Forge, opts *Opts) (*changelog.Opts, error) {
+	changelogOpts := &changelog.Opts{
+		Format:       opts.ChangelogFormat,
+		Rules:        opts.Rules,
+		IssueURL:     opts.IssueURL,
+		CommitURL:    opts.CommitURL,
+		CompareURL:   opts.CompareURL,
+		Contributors: opts.Contributors,
+	}
+	if f != nil {
+		changelogOpts.IssueURL = cmp.Or(opts.IssueURL, f.IssueURL())
+		changelogOpts.CommitURL = cmp.Or(opts.CommitURL, f.CommitURL())
+		changelogOpts.CompareURL = cmp.Or(opts.CompareURL, f.CompareURL())
+	}
+	if opts.TemplatePath != "" {
+		tpl, err := readTemplate(root, opts.TemplatePath)
+		if err != nil {
+			return nil, err
+		}
+		changelogOpts.Template = tpl
+	}
+	return changelogOpts, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// readTemplate reads the template at the given path. Relative paths are
+// resolved against root.
+func readTemplate(root string, filepath string) (string, error) {
+	tpl, err := os.ReadFile(resolve(root, filepath))
+	if err != nil {
+		return "", fmt.Errorf("Error reading changelog template. Reason: '%s'", err)
+	}
+	return string(tpl), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// detectForge returns the forge hosting the repository or nil if it is
+// unknown. The forge is detected from the remote the release is pushed to,
+// `origin` otherwise.
+func detectForge(root string, opts *Opts) (*forge.Forge, error) {
+	if opts.RepoURL != "" {
+		return forge.New(opts.Forge, opts.RepoURL)
+	}
+	remote := cmp.Or(opts.Remote, "origin")
+	remoteURL, err := git.GetRemoteURL(remote, &git.GitOpts{RootDir: root})
+	if err != nil {
+		return nil, err
+	}
+	// NOTE(joel): The remote may be given as a URL instead of a name.
+	if remoteURL == "" {
+		remoteURL = remote
+	}
+	return forge.Detect(opts.Forge, remoteURL), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// latestStable returns the first tag that is not a pre-release. Tags are
+// expected to be sorted in descending order.
+func latestStable(tags []*semver.Version) *semver.Version {
+	for _, t := range tags {
+		if t.Prerelease() == "" {
+			return t
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// previousTag returns the tag with the highest precedence below v. Tags are
+// expected to be sorted in descending order.
+func previousTag(v *semver.Version, tags []*semver.Version) *semver.Version {
+	for _, t := range tags {
+		if t.LessThan(v) {
+			return t
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// commitsSince returns all commits since the given tag. If tag is nil, all
+// commits are returned.
+func commitsSince(tag *semver.Version, gitOpts *git.GitOpts) ([]*git.Commit, error) {
+	// NOTE(joel): Get sha of the tag to use as a reference point from where
+	// new commits are analyzed. If there is no tag, we set the sha to an empty
+	// string.
+	var sha string
+	if tag != nil {
+		var err error
+		sha, err = git.GetTagHead(tag.Original, gitOpts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return git.GetCommits(sha, gitOpts)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// withoutReleases returns commits without the release commits of the given
+// tags, e.g. the `chore(release): v2.0.0-rc.1` commit of a pre-release.
+func withoutReleases(commits []*git.Commit, tags []*semver.Version, ropts *git.ReleaseOpts) []*git.Commit {
+	subjects := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		subjects[git.ReleaseSubject(t, ropts)] = true
+	}
+	filtered := make([]*git.Commit, 0, len(commits))
+	for _, c := range commits {
+		if !subjects[c.Subject] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// resolve resolves a path relative to the git root. Absolute paths are
+// returned unchanged.
+func resolve(root string, filepath string) string {
+	if path.IsAbs(filepath) {
+		return filepath
+	}
+	return path.Join(root, filepath)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// versionFileFor returns the version file and the function replacing the
+// version in it for the given project type.
+func versionFileFor(projectType string) (string, func(*semver.Version, []byte) []byte, error) {
+	switch projectType {
+	case "node":
+		return node.VersionFile, node.ReplaceVersion, nil
+	case "python":
+		return python.VersionFile, python.ReplaceVersion, nil
+	case "go":
+		return golang.VersionFile, golang.ReplaceVersion, nil
+	default:
+		return "", nil, errors.New("unsupported project type")
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// readOptional reads the file at the given path. A missing file is not an
+// error and results in empty content.
+func readOptional(filepath string) ([]byte, error) {
+	content, err := os.ReadFile(filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading '%s': %w", filepath, err)
+	}
+	return content, nil
+}
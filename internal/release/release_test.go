@@ -0,0 +1,638 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/check"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createGitRepo(t *testing.T) (string, func()) {
+	tempDir, err := os.MkdirTemp("", "git-repo-*")
+	if err != nil {
+		t.Fatalf("Error creating temp directory: %v", err)
+	}
+
+	cleanUpFunc := func() {
+		os.RemoveAll(tempDir)
+	}
+
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test.user@example.com"},
+	} {
+		runGit(t, tempDir, args...)
+	}
+
+	return tempDir, cleanUpFunc
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Error running git %v: %v (%s)", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func writeFile(t *testing.T, filepath string, content string) {
+	if err := os.WriteFile(filepath, []byte(content), 0644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestNewPlan(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "1.0.0"}`)
+	writeFile(t, path.Join(cwd, "CHANGELOG.md"), "# Changelog\n\n## 1.0.0 - 2006-01-02\n")
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "chore: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+
+	plan, err := NewPlan(&Opts{
+		RootDir:       cwd,
+		ChangelogPath: "./CHANGELOG.md",
+		ProjectType:   "node",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.1.0", plan.Version.ToString())
+	assert.Len(t, plan.Commits, 1)
+	assert.Contains(t, string(plan.Changelog), "### Features\n- add feature")
+	require.Len(t, plan.Files, 2)
+	assert.Contains(t, string(plan.Files[0].New), "## 1.1.0")
+	assert.Equal(t, `{"version": "1.1.0"}`, string(plan.Files[1].New))
+	assert.Equal(t, "1.0.0", plan.Notes.PreviousVersion)
+	assert.Equal(t, "minor", plan.Notes.Bump.Type)
+	require.Len(t, plan.Notes.Sections, 1)
+	assert.Equal(t, "add feature", plan.Notes.Sections[0].Commits[0].Message)
+
+	diff := plan.Diff()
+	assert.Contains(t, diff, "--- a/CHANGELOG.md\n+++ b/CHANGELOG.md\n")
+	assert.Contains(t, diff, "-{\"version\": \"1.0.0\"}\n\\ No newline at end of file\n+{\"version\": \"1.1.0\"}\n")
+
+	// NOTE(joel): Computing the plan must not touch the repository.
+	assert.Empty(t, runGit(t, cwd, "status", "--porcelain"))
+	assert.Equal(t, "v1.0.0", runGit(t, cwd, "tag"))
+}
+
+func TestNewPlanForgeLinks(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "1.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "chore: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "fix: fix bug\n\nCloses #7")
+	runGit(t, cwd, "remote", "add", "origin", "git@gitlab.com:group/repo.git")
+	sha := runGit(t, cwd, "rev-parse", "HEAD")
+
+	plan, err := NewPlan(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "gitlab", plan.Forge.Type)
+	assert.Contains(t, string(plan.Changelog), "## [1.0.1](https://gitlab.com/group/repo/-/compare/v1.0.0...v1.0.1)")
+	assert.Contains(t, string(plan.Changelog), fmt.Sprintf(
+		"- fix bug ([%s](https://gitlab.com/group/repo/-/commit/%s)), closes [#7](https://gitlab.com/group/repo/-/issues/7)",
+		sha[:7], sha,
+	))
+
+	// NOTE(joel): The forge is detected from the remote the release is pushed
+	// to.
+	runGit(t, cwd, "remote", "add", "upstream", "https://github.com/owner/repo.git")
+	plan, err = NewPlan(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node", Remote: "upstream"})
+	require.NoError(t, err)
+	assert.Equal(t, "github", plan.Forge.Type)
+	assert.Equal(t, "https://github.com/owner/repo", plan.Forge.URL)
+
+	// NOTE(joel): Configured URLs take precedence over the detected forge.
+	plan, err = NewPlan(&Opts{
+		RootDir:       cwd,
+		ChangelogPath: "./CHANGELOG.md",
+		ProjectType:   "node",
+		Forge:         "gitea",
+		RepoURL:       "https://git.example.com/owner/repo",
+		IssueURL:      "https://tracker.example.com/%s",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gitea", plan.Forge.Type)
+	assert.Contains(t, string(plan.Changelog), "## [1.0.1](https://git.example.com/owner/repo/compare/v1.0.0...v1.0.1)")
+	assert.Contains(t, string(plan.Changelog), "closes [#7](https://tracker.example.com/7)")
+}
+
+func TestNewPlanReleaseNotes(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "1.0.0"}`)
+	writeFile(t, path.Join(cwd, "CHANGELOG.md"), "# Changelog\n\n## 1.0.0 - 2006-01-02\n")
+	writeFile(t, path.Join(cwd, "notes.tpl"), "{{ range .Sections }}{{ range .Commits }}* {{ .Message }}\n{{ end }}{{ end }}")
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "chore: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+
+	opts := &Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node"}
+	plan, err := NewPlan(opts)
+	require.NoError(t, err)
+	assert.Equal(t, plan.Changelog, plan.ReleaseNotes)
+	assert.NotContains(t, string(plan.ReleaseNotes), "1.0.0")
+
+	opts.ReleaseNotesTemplatePath = "./notes.tpl"
+	plan, err = NewPlan(opts)
+	require.NoError(t, err)
+	assert.Equal(t, "* add feature", string(plan.ReleaseNotes))
+	assert.Contains(t, string(plan.Changelog), "### Features\n- add feature")
+
+	opts.ReleaseNotesTemplatePath = "./missing.tpl"
+	_, err = NewPlan(opts)
+	assert.ErrorContains(t, err, "Error reading changelog template")
+}
+
+func TestNewPlanKeepAChangelog(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "1.0.0"}`)
+	writeFile(t, path.Join(cwd, "CHANGELOG.md"), "# Changelog\n\n## [Unreleased]\n\n### Added\n- Documentation site\n")
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "chore: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+
+	plan, err := NewPlan(&Opts{
+		RootDir:         cwd,
+		ChangelogPath:   "./CHANGELOG.md",
+		ProjectType:     "node",
+		ChangelogFormat: "keepachangelog",
+	})
+	require.NoError(t, err)
+
+	// NOTE(joel): The hand-written entries are part of the release notes.
+	assert.Contains(t, string(plan.Changelog), "### Added\n- Documentation site\n- add feature")
+	assert.Equal(t, plan.Changelog, plan.ReleaseNotes)
+	assert.NotContains(t, string(plan.Changelog), "Unreleased")
+}
+
+func TestApply(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "0.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial commit")
+
+	plan, err := NewPlan(&Opts{
+		RootDir:       cwd,
+		ChangelogPath: "./CHANGELOG.md",
+		ProjectType:   "node",
+	})
+	require.NoError(t, err)
+	require.NoError(t, plan.Apply())
+
+	assert.Equal(t, "v1.0.0", runGit(t, cwd, "tag"))
+	assert.Empty(t, runGit(t, cwd, "status", "--porcelain"))
+	content, err := os.ReadFile(path.Join(cwd, "package.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"version": "1.0.0"}`, string(content))
+}
+
+func TestApplyPush(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	remote := t.TempDir()
+	runGit(t, remote, "init", "--bare")
+	runGit(t, cwd, "remote", "add", "origin", remote)
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "0.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial commit")
+	branch := runGit(t, cwd, "branch", "--show-current")
+	runGit(t, cwd, "push", "-q", "origin", branch)
+	runGit(t, cwd, "tag", "local")
+
+	opts := &Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node", Remote: "origin"}
+	plan, err := NewPlan(opts)
+	require.NoError(t, err)
+	require.NoError(t, plan.Apply())
+
+	// NOTE(joel): Only the branch and the new tag are pushed.
+	head := runGit(t, cwd, "rev-parse", "HEAD")
+	assert.Equal(t, head, runGit(t, remote, "rev-parse", branch))
+	assert.Equal(t, head, runGit(t, remote, "rev-parse", "v1.0.0^{commit}"))
+	assert.Equal(t, "v1.0.0", runGit(t, remote, "tag"))
+
+	// NOTE(joel): Someone else pushed in the meantime. The push is rejected
+	// and the release is rolled back, locally and on the remote.
+	other := t.TempDir()
+	runGit(t, other, "clone", "-q", remote, ".")
+	runGit(t, other, "-c", "user.name=Other", "-c", "user.email=other@example.com", "commit", "--allow-empty", "-m", "fix: other fix")
+	runGit(t, other, "push", "-q", "origin", branch)
+	remoteHead := runGit(t, remote, "rev-parse", branch)
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "fix: some fix")
+	head = runGit(t, cwd, "rev-parse", "HEAD")
+	plan, err = NewPlan(opts)
+	require.NoError(t, err)
+	err = plan.Apply()
+	assert.EqualError(t, err, fmt.Sprintf(
+		"Error pushing to 'origin'. Branch '%s' is behind the remote branch (non-fast-forward), so nothing was pushed. Pull the changes first\n",
+		branch,
+	))
+
+	assert.Equal(t, head, runGit(t, cwd, "rev-parse", "HEAD"))
+	assert.Equal(t, "local\nv1.0.0", runGit(t, cwd, "tag"))
+	assert.Empty(t, runGit(t, cwd, "status", "--porcelain"))
+	assert.Equal(t, remoteHead, runGit(t, remote, "rev-parse", branch))
+	assert.Equal(t, "v1.0.0", runGit(t, remote, "tag"))
+}
+
+func TestNewPlanExistingSection(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "1.0.0"}`)
+	writeFile(t, path.Join(cwd, "CHANGELOG.md"), "# Changelog\n\n## 1.0.0 - 2006-01-02\n")
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "chore: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+
+	// NOTE(joel): A section left over from a failed release is replaced. The
+	// leftover is uncommitted, so the dirty check is skipped.
+	writeFile(t, path.Join(cwd, "CHANGELOG.md"), "# Changelog\n\n## 1.1.0 - 2006-01-03\n\n- stale\n\n## 1.0.0 - 2006-01-02\n")
+	opts := &Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node", AllowDirty: true}
+	plan, err := NewPlan(opts)
+	require.NoError(t, err)
+	content := string(plan.Files[0].New)
+	assert.Equal(t, 1, strings.Count(content, "## 1.1.0"))
+	assert.NotContains(t, content, "- stale")
+	assert.Contains(t, content, "- add feature")
+
+	// NOTE(joel): A section of an already tagged version is never replaced.
+	// The existing tag fails the pre-flight checks unless skipped.
+	runGit(t, cwd, "branch", "other")
+	runGit(t, cwd, "checkout", "-q", "other")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "chore: release")
+	runGit(t, cwd, "tag", "v1.1.0")
+	runGit(t, cwd, "checkout", "-q", "-")
+
+	_, err = NewPlan(opts)
+	assert.ErrorContains(t, err, "  - tag: Tag 'v1.1.0' already exists\n")
+
+	opts.SkipChecks = []string{check.Tag}
+	_, err = NewPlan(opts)
+	assert.EqualError(t, err, "Changelog already contains a section for the released version '1.1.0' (tag 'v1.1.0')")
+}
+
+func TestNewPlanDirty(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "0.0.0"}`)
+	writeFile(t, path.Join(cwd, "README.md"), "readme")
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial commit")
+	writeFile(t, path.Join(cwd, "README.md"), "changed")
+	writeFile(t, path.Join(cwd, ".env"), "SECRET=1")
+
+	opts := &Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node"}
+	_, err := NewPlan(opts)
+	assert.EqualError(t, err, "Pre-flight checks failed:\n"+
+		"  - clean: Working tree has uncommitted changes:\n"+
+		"       M README.md\n"+
+		"      ?? .env\n"+
+		"    Commit or stash them, or use --allow-dirty\n"+
+		"Fix the problems above or skip single checks with --skip-check <name>")
+
+	// NOTE(joel): With AllowDirty, only the files of the release are
+	// committed. Other changes stay in the working tree.
+	runGit(t, cwd, "add", "README.md")
+	opts.AllowDirty = true
+	plan, err := NewPlan(opts)
+	require.NoError(t, err)
+	require.NoError(t, plan.Apply())
+
+	assert.Equal(t, "CHANGELOG.md\npackage.json", runGit(t, cwd, "show", "--format=", "--name-only", "HEAD"))
+	assert.Equal(t, "M  README.md\n?? .env", runGit(t, cwd, "status", "--porcelain"))
+}
+
+func TestNewPlanBranchChecks(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "0.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial commit")
+	runGit(t, cwd, "checkout", "-q", "-b", "feature/foo")
+	writeFile(t, path.Join(cwd, ".env"), "SECRET=1")
+
+	// NOTE(joel): The branch rules are checked before the version is computed,
+	// so the failures are reported together with the other checks.
+	opts := &Opts{
+		RootDir:     cwd,
+		ProjectType: "node",
+		Branches:    []Branch{{Name: "main"}, {Name: "release/*"}},
+	}
+	_, err := NewPlan(opts)
+	assert.EqualError(t, err, "Pre-flight checks failed:\n"+
+		"  - clean: Working tree has uncommitted changes:\n"+
+		"      ?? .env\n"+
+		"    Commit or stash them, or use --allow-dirty\n"+
+		"  - branch: Branch 'feature/foo' is not configured as a release branch. Releases can only be created from main, release/*\n"+
+		"Fix the problems above or skip single checks with --skip-check <name>")
+
+	runGit(t, cwd, "checkout", "-q", "--detach")
+	_, err = NewPlan(opts)
+	assert.ErrorContains(t, err, "  - branch: HEAD is not on a branch. Releases can only be created from main, release/*\n")
+	assert.ErrorContains(t, err, "  - detached: HEAD is detached. Check out the branch to release from\n")
+}
+
+func TestNewPlanNotDue(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "1.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "chore: update deps")
+
+	// NOTE(joel): The existing tag of the version doesn't fail the checks.
+	plan, err := NewPlan(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node"})
+	require.NoError(t, err)
+	assert.False(t, plan.IsDue())
+	assert.Empty(t, plan.Files)
+}
+
+func TestNewPlanUnsupportedType(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: initial commit")
+
+	_, err := NewPlan(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "rust"})
+	assert.EqualError(t, err, "unsupported project type")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestNextVersion(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "chore: initial commit")
+
+	// NOTE(joel): Without tags a first release is always due.
+	next, err := NextVersion(&Opts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.True(t, next.IsDue())
+	assert.Equal(t, "1.0.0", next.Version.ToString())
+
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "docs: update readme")
+
+	next, err = NextVersion(&Opts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.False(t, next.IsDue())
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "fix: fix bug")
+
+	next, err = NextVersion(&Opts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.True(t, next.IsDue())
+	assert.Equal(t, "1.0.1", next.Version.ToString())
+}
+
+func TestNextVersionPrerelease(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "chore: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat!: breaking change")
+
+	next, err := NextVersion(&Opts{RootDir: cwd, Prerelease: "rc"})
+	require.NoError(t, err)
+	assert.True(t, next.IsDue())
+	assert.Equal(t, "2.0.0-rc.1", next.Version.ToString())
+	assert.Equal(t, "v1.0.0", next.Previous.Original)
+	assert.Len(t, next.Commits, 1)
+
+	runGit(t, cwd, "tag", "v2.0.0-rc.1")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "fix: fix bug")
+
+	// NOTE(joel): The counter is incremented and the changelog only covers the
+	// commits since the previous pre-release.
+	next, err = NextVersion(&Opts{RootDir: cwd, Prerelease: "rc"})
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0-rc.2", next.Version.ToString())
+	assert.Equal(t, "v2.0.0-rc.1", next.Previous.Original)
+	require.Len(t, next.Commits, 1)
+	assert.Equal(t, "fix bug", next.Commits[0].Message)
+
+	runGit(t, cwd, "tag", "v2.0.0-rc.2")
+
+	// NOTE(joel): A stable release graduates the pre-release and covers all
+	// commits since the latest stable release.
+	next, err = NextVersion(&Opts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.True(t, next.IsDue())
+	assert.Equal(t, "2.0.0", next.Version.ToString())
+	assert.Equal(t, "v1.0.0", next.Previous.Original)
+	assert.Len(t, next.Commits, 2)
+
+	_, err = NextVersion(&Opts{RootDir: cwd, Prerelease: "r_c"})
+	assert.Error(t, err)
+}
+
+func TestNextVersionPrereleaseNotDue(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "chore: update deps")
+
+	// NOTE(joel): No pre-release of the latest stable version is created.
+	next, err := NextVersion(&Opts{RootDir: cwd, Prerelease: "rc"})
+	require.NoError(t, err)
+	assert.False(t, next.IsDue())
+	assert.Equal(t, "1.0.0", next.Version.ToString())
+
+	// NOTE(joel): Same for the channel of a branch rule.
+	runGit(t, cwd, "checkout", "-b", "next")
+	next, err = NextVersion(&Opts{RootDir: cwd, Branches: []Branch{{Name: "next", Prerelease: "next"}}})
+	require.NoError(t, err)
+	assert.False(t, next.IsDue())
+	assert.Equal(t, "1.0.0", next.Version.ToString())
+}
+
+func TestNextVersionPrereleaseSinceRC(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "1.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+	runGit(t, cwd, "tag", "v1.1.0-rc.1")
+
+	// NOTE(joel): rc.1 then nothing.
+	next, err := NextVersion(&Opts{RootDir: cwd, Prerelease: "rc"})
+	require.NoError(t, err)
+	assert.False(t, next.IsDue())
+	assert.Equal(t, "1.1.0-rc.1", next.Version.ToString())
+	assert.Empty(t, next.Commits)
+
+	plan, err := NewPlan(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node", Prerelease: "rc"})
+	require.NoError(t, err)
+	assert.False(t, plan.IsDue())
+
+	// NOTE(joel): rc.1 then chore only.
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "chore: ci")
+	next, err = NextVersion(&Opts{RootDir: cwd, Prerelease: "rc"})
+	require.NoError(t, err)
+	assert.False(t, next.IsDue())
+	assert.Equal(t, "1.1.0-rc.1", next.Version.ToString())
+
+	// NOTE(joel): A fix warrants the next pre-release of the same base. The
+	// release notes name the fix as the reason.
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "fix: fix bug")
+	plan, err = NewPlan(&Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node", Prerelease: "rc"})
+	require.NoError(t, err)
+	assert.True(t, plan.IsDue())
+	assert.Equal(t, "1.1.0-rc.2", plan.Version.ToString())
+	assert.Equal(t, "minor", plan.Notes.Bump.Type)
+	assert.Equal(t, "1 commit triggers a patch release", plan.Notes.Bump.Reason)
+	assert.Len(t, plan.Notes.Bump.Commits, 1)
+}
+
+func TestNextVersionGraduation(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "1.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+
+	opts := &Opts{RootDir: cwd, ChangelogPath: "./CHANGELOG.md", ProjectType: "node", Prerelease: "rc"}
+	plan, err := NewPlan(opts)
+	require.NoError(t, err)
+	require.NoError(t, plan.Apply())
+	assert.Equal(t, "chore(release): v1.1.0-rc.1", runGit(t, cwd, "log", "-1", "--pretty=%s"))
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "fix: fix bug")
+
+	// NOTE(joel): The graduated release covers the commits of its pre-releases
+	// but not their release commits.
+	opts.Prerelease = ""
+	plan, err = NewPlan(opts)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", plan.Version.ToString())
+	require.Len(t, plan.Commits, 2)
+	assert.Equal(t, "fix bug", plan.Commits[0].Message)
+	assert.Equal(t, "add feature", plan.Commits[1].Message)
+	assert.NotContains(t, string(plan.Changelog), "release:")
+}
+
+func TestLatestTag(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: initial commit")
+	for _, tag := range []string{"v1.3.0", "v1.4.0", "v1.4.2", "v1.5.0-rc.1", "v2.0.0"} {
+		runGit(t, cwd, "tag", tag)
+	}
+
+	tag, err := LatestTag(&Opts{RootDir: cwd}, "~1.4")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.4.2", tag.Original)
+
+	tag, err = LatestTag(&Opts{RootDir: cwd}, "^3.0.0")
+	require.NoError(t, err)
+	assert.Nil(t, tag)
+
+	_, err = LatestTag(&Opts{RootDir: cwd}, "~x.y")
+	assert.Error(t, err)
+}
+
+func TestNextVersionBranches(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	branches := []Branch{
+		{Name: "main"},
+		{Name: "next", Prerelease: "next"},
+		{Name: "1.x"},
+	}
+
+	runGit(t, cwd, "checkout", "-b", "main")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "chore: initial commit")
+	runGit(t, cwd, "tag", "v1.0.0")
+
+	// NOTE(joel): Pre-release channel
+	runGit(t, cwd, "checkout", "-b", "next")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+	next, err := NextVersion(&Opts{RootDir: cwd, Branches: branches})
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0-next.1", next.Version.ToString())
+
+	// NOTE(joel): Maintenance branch
+	runGit(t, cwd, "checkout", "-b", "1.x", "main")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "fix: fix bug")
+	next, err = NextVersion(&Opts{RootDir: cwd, Branches: branches})
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.1", next.Version.ToString())
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat!: breaking change")
+	_, err = NextVersion(&Opts{RootDir: cwd, Branches: branches})
+	assert.EqualError(t, err, "Version '2.0.0' is outside of the allowed range '1.x' of branch '1.x'")
+
+	// NOTE(joel): Unknown branch
+	runGit(t, cwd, "checkout", "-b", "feature/foo")
+	_, err = NextVersion(&Opts{RootDir: cwd, Branches: branches})
+	assert.EqualError(t, err, "Branch 'feature/foo' is not configured as a release branch")
+
+	// NOTE(joel): Without branch rules all branches are allowed.
+	next, err = NextVersion(&Opts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", next.Version.ToString())
+}
+
+func TestNextVersionRange(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "chore: initial commit")
+	runGit(t, cwd, "tag", "v2.4.0")
+	runGit(t, cwd, "commit", "--allow-empty", "-m", "feat!: breaking change")
+
+	_, err := NextVersion(&Opts{RootDir: cwd, VersionRange: ">=2.0.0 <3.0.0"})
+	assert.EqualError(t, err, "Version '3.0.0' does not satisfy the version range '>=2.0.0 <3.0.0'")
+
+	_, err = NextVersion(&Opts{RootDir: cwd, MaxVersion: "2.x"})
+	assert.EqualError(t, err, "Version '3.0.0' does not satisfy the version range '<=2.x'")
+
+	next, err := NextVersion(&Opts{RootDir: cwd, VersionRange: "^2 || ^3", MaxVersion: "3.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.0", next.Version.ToString())
+}
@@ -0,0 +1,173 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/semver"
+)
+
+// NOTE(joel): Declare package global variables to hold the steps of a release
+// so that failures can be injected in tests.
+var (
+	writeReleaseFile = os.WriteFile
+	createCommit     = git.CreateCommit
+	createTag        = git.CreateTag
+	push             = git.Push
+)
+
+// transaction records every change a release makes to the repository, so that
+// all of them can be rolled back if a later step fails.
+type transaction struct {
+	root string
+	// NOTE(joel): Content of the files before they were first written, in the
+	// order they were written.
+	snapshots []*snapshot
+	// NOTE(joel): Files that may have been added to the index.
+	staged []string
+	// NOTE(joel): Whether the release commit was attempted and the sha of HEAD
+	// before it. Head is empty if the branch had no commits yet.
+	committed bool
+	head      string
+	// NOTE(joel): Release tag. Empty if it wasn't attempted or already existed
+	// before.
+	tag string
+}
+
+// snapshot is the state of a file before the release modified it.
+type snapshot struct {
+	path    string
+	content []byte
+	mode    fs.FileMode
+	// NOTE(joel): Whether the file existed. Files created by the release are
+	// removed on rollback.
+	exists bool
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// newTransaction returns an empty transaction for the repository at root.
+func newTransaction(root string) *transaction {
+	return &transaction{root: root}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// writeFile snapshots the file (once) and writes the content to it.
+func (t *transaction) writeFile(path string, content []byte) error {
+	if !slices.ContainsFunc(t.snapshots, func(s *snapshot) bool { return s.path == path }) {
+		s := &snapshot{path: path, mode: 0644}
+		info, err := os.Stat(path)
+		switch {
+		case err == nil:
+			s.exists = true
+			s.mode = info.Mode().Perm()
+			if s.content, err = os.ReadFile(path); err != nil {
+				return err
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+		t.snapshots = append(t.snapshots, s)
+	}
+
+	return writeReleaseFile(path, content, 0644)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// commit creates the release commit of the given files and records the
+// previous HEAD.
+func (t *transaction) commit(v *semver.Version, files []string, ropts *git.ReleaseOpts) error {
+	opts := &git.GitOpts{RootDir: t.root}
+	head, err := git.GetHead(opts)
+	if err != nil {
+		return err
+	}
+
+	// NOTE(joel): Record the commit before creating it. The files are added to
+	// the index first, so they must be unstaged even if the commit fails.
+	t.staged = files
+	t.committed = true
+	t.head = head
+	return createCommit(v, files, ropts, opts)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// tagRelease records the release tag and creates it. A tag that exists
+// already is not recorded, so it is never deleted on rollback.
+func (t *transaction) tagRelease(v *semver.Version, ropts *git.ReleaseOpts) error {
+	opts := &git.GitOpts{RootDir: t.root}
+	tag := fmt.Sprintf("v%s", v.ToString())
+	exists, err := git.TagExists(tag, opts)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		t.tag = tag
+	}
+	return createTag(v, ropts, opts)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// rollback undoes all recorded changes in reverse order: deletes the tag,
+// resets the branch to the previous HEAD, unstages the files and restores
+// their snapshots. Steps that failed before changing anything are skipped. It
+// continues on errors and returns all of them.
+//
+// NOTE(joel): Changes of the release files that were staged before the
+// release are unstaged as well, their content is restored though.
+func (t *transaction) rollback() error {
+	opts := &git.GitOpts{RootDir: t.root}
+	var errs []error
+
+	if t.tag != "" {
+		exists, err := git.TagExists(t.tag, opts)
+		if err == nil && exists {
+			err = git.DeleteTag(t.tag, opts)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if t.committed {
+		head, err := git.GetHead(opts)
+		if err == nil && head != t.head {
+			err = git.ResetSoft(t.head, opts)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(t.staged) > 0 {
+		if err := git.Unstage(t.staged, opts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for i := len(t.snapshots) - 1; i >= 0; i-- {
+		s := t.snapshots[i]
+		var err error
+		if s.exists {
+			err = os.WriteFile(s.path, s.content, s.mode)
+		} else {
+			err = os.Remove(s.path)
+			if errors.Is(err, fs.ErrNotExist) {
+				err = nil
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,171 @@
+package release
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/joelvoss/release-lit/internal/git"
+	"github.com/joelvoss/release-lit/internal/semver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRollback(t *testing.T) {
+	tests := []struct {
+		name string
+		// NOTE(joel): Injects the failure into the release steps of the repo.
+		inject func(t *testing.T, cwd string)
+		err    string
+	}{
+		{
+			name: "write changelog",
+			inject: func(t *testing.T, cwd string) {
+				writeReleaseFile = func(string, []byte, os.FileMode) error {
+					return errors.New("disk full")
+				}
+			},
+			err: "disk full",
+		},
+		{
+			name: "write version file",
+			inject: func(t *testing.T, cwd string) {
+				calls := 0
+				writeReleaseFile = func(name string, data []byte, perm os.FileMode) error {
+					if calls++; calls == 2 {
+						return errors.New("disk full")
+					}
+					return os.WriteFile(name, data, perm)
+				}
+			},
+			err: "disk full",
+		},
+		{
+			name: "commit",
+			inject: func(t *testing.T, cwd string) {
+				createCommit = func(v *semver.Version, files []string, ropts *git.ReleaseOpts, opts *git.GitOpts) error {
+					// NOTE(joel): Fail after the files were added to the index.
+					runGit(t, cwd, append([]string{"add", "--"}, files...)...)
+					return errors.New("error creating release commit")
+				}
+			},
+			err: "error creating release commit",
+		},
+		{
+			name: "tag",
+			inject: func(t *testing.T, cwd string) {
+				createTag = func(*semver.Version, *git.ReleaseOpts, *git.GitOpts) error {
+					return errors.New("error creating release tag")
+				}
+			},
+			err: "error creating release tag",
+		},
+		{
+			name: "after tag",
+			inject: func(t *testing.T, cwd string) {
+				createTag = func(v *semver.Version, ropts *git.ReleaseOpts, opts *git.GitOpts) error {
+					if err := git.CreateTag(v, ropts, opts); err != nil {
+						return err
+					}
+					return errors.New("interrupted")
+				}
+			},
+			err: "interrupted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cwd, cleanUp := createGitRepo(t)
+			defer cleanUp()
+			t.Cleanup(func() {
+				writeReleaseFile = os.WriteFile
+				createCommit = git.CreateCommit
+				createTag = git.CreateTag
+			})
+
+			writeFile(t, path.Join(cwd, "package.json"), `{"version": "0.0.0"}`)
+			runGit(t, cwd, "add", ".")
+			runGit(t, cwd, "commit", "-m", "chore: initial commit")
+			runGit(t, cwd, "tag", "v0.0.0")
+			runGit(t, cwd, "commit", "--allow-empty", "-m", "feat: add feature")
+			head := runGit(t, cwd, "rev-parse", "HEAD")
+
+			plan, err := NewPlan(&Opts{
+				RootDir:       cwd,
+				ChangelogPath: "./CHANGELOG.md",
+				ProjectType:   "node",
+			})
+			require.NoError(t, err)
+
+			tt.inject(t, cwd)
+			err = plan.Apply()
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+
+			// NOTE(joel): The repository is back in its state before the release.
+			assert.Equal(t, head, runGit(t, cwd, "rev-parse", "HEAD"))
+			assert.Equal(t, "v0.0.0", runGit(t, cwd, "tag"))
+			assert.Empty(t, runGit(t, cwd, "status", "--porcelain"))
+			content, err := os.ReadFile(path.Join(cwd, "package.json"))
+			require.NoError(t, err)
+			assert.Equal(t, `{"version": "0.0.0"}`, string(content))
+			_, err = os.Stat(path.Join(cwd, "CHANGELOG.md"))
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestRollbackFirstCommit(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	tx := newTransaction(cwd)
+	v, _ := semver.Parse("v1.0.0")
+	changelog := path.Join(cwd, "CHANGELOG.md")
+	require.NoError(t, tx.writeFile(changelog, []byte("# Changelog\n")))
+	require.NoError(t, tx.commit(v, []string{changelog}, nil))
+	require.NoError(t, tx.tagRelease(v, nil))
+	require.NoError(t, tx.rollback())
+
+	// NOTE(joel): The branch has no commits again.
+	assert.Empty(t, runGit(t, cwd, "tag"))
+	assert.Empty(t, runGit(t, cwd, "status", "--porcelain"))
+	head, err := git.GetHead(&git.GitOpts{RootDir: cwd})
+	require.NoError(t, err)
+	assert.Empty(t, head)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func TestApplyRollbackSigning(t *testing.T) {
+	cwd, cleanUp := createGitRepo(t)
+	defer cleanUp()
+
+	writeFile(t, path.Join(cwd, "package.json"), `{"version": "0.0.0"}`)
+	runGit(t, cwd, "add", ".")
+	runGit(t, cwd, "commit", "-m", "feat: initial commit")
+	head := runGit(t, cwd, "rev-parse", "HEAD")
+
+	plan, err := NewPlan(&Opts{
+		RootDir:       cwd,
+		ChangelogPath: "./CHANGELOG.md",
+		ProjectType:   "node",
+		Git: &git.ReleaseOpts{
+			Sign:       true,
+			SignFormat: git.SignFormatSSH,
+			SigningKey: path.Join(cwd, "missing-key"),
+		},
+	})
+	require.NoError(t, err)
+
+	err = plan.Apply()
+	assert.ErrorContains(t, err, "Error creating signed release commit")
+	assert.Equal(t, head, runGit(t, cwd, "rev-parse", "HEAD"))
+	assert.Empty(t, runGit(t, cwd, "status", "--porcelain"))
+}
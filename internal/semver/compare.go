@@ -0,0 +1,122 @@
+package semver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Compare compares the precedence of v and o according to
+// https://semver.org/#spec-item-11. It returns -1 if v is lower than o, 0 if
+// both are equal and 1 if v is greater than o. Build metadata is ignored.
+func (v *Version) Compare(o *Version) int {
+	if d := compareUint(v.major, o.major); d != 0 {
+		return d
+	}
+	if d := compareUint(v.minor, o.minor); d != 0 {
+		return d
+	}
+	if d := compareUint(v.patch, o.patch); d != 0 {
+		return d
+	}
+
+	// NOTE(joel): A pre-release version has a lower precedence than the
+	// associated normal version.
+	switch {
+	case v.pre == o.pre:
+		return 0
+	case v.pre == "":
+		return 1
+	case o.pre == "":
+		return -1
+	}
+
+	return comparePrerelease(v.pre, o.pre)
+}
+
+// LessThan reports whether v has a lower precedence than o.
+func (v *Version) LessThan(o *Version) bool {
+	return v.Compare(o) < 0
+}
+
+// GreaterThan reports whether v has a higher precedence than o.
+func (v *Version) GreaterThan(o *Version) bool {
+	return v.Compare(o) > 0
+}
+
+// Equal reports whether v and o have the same precedence. Build metadata is
+// ignored, so `1.0.0+a` and `1.0.0+b` are equal.
+func (v *Version) Equal(o *Version) bool {
+	return v.Compare(o) == 0
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Collection is a list of versions that can be sorted with the sort package.
+// It sorts in ascending order of precedence.
+type Collection []*Version
+
+func (c Collection) Len() int {
+	return len(c)
+}
+
+func (c Collection) Less(i, j int) bool {
+	return c[i].LessThan(c[j])
+}
+
+func (c Collection) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// comparePrerelease compares two non-empty pre-release strings identifier by
+// identifier. Numeric identifiers are compared numerically and always have a
+// lower precedence than alphanumeric identifiers, which are compared in ASCII
+// sort order. A larger set of identifiers has a higher precedence if all
+// preceding identifiers are equal.
+func comparePrerelease(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if d := compareIdentifier(aParts[i], bParts[i]); d != 0 {
+			return d
+		}
+	}
+
+	return compareUint(uint64(len(aParts)), uint64(len(bParts)))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// compareIdentifier compares a single pre-release identifier.
+func compareIdentifier(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	aIsNum := aErr == nil && containsOnly(a, num)
+	bIsNum := bErr == nil && containsOnly(b, num)
+
+	switch {
+	case aIsNum && bIsNum:
+		return compareUint(aNum, bNum)
+	case aIsNum:
+		return -1
+	case bIsNum:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,83 @@
+package semver
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"2.1.0", "2.0.0", 1},
+		{"2.1.1", "2.1.0", 1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		{"1.0.0-rc.1+build", "1.0.0-rc.1", 0},
+		// NOTE(joel): Example from https://semver.org/#spec-item-11
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-rc.10", "1.0.0-rc.2", 1},
+		{"1.0.0-1a", "1.0.0-2", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" vs "+test.b, func(t *testing.T) {
+			t.Parallel()
+
+			a, err := Parse(test.a)
+			assert.NoError(t, err)
+			b, err := Parse(test.b)
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expected, a.Compare(b))
+			assert.Equal(t, -test.expected, b.Compare(a))
+			assert.Equal(t, test.expected < 0, a.LessThan(b))
+			assert.Equal(t, test.expected > 0, a.GreaterThan(b))
+			assert.Equal(t, test.expected == 0, a.Equal(b))
+		})
+	}
+}
+
+func TestCollection(t *testing.T) {
+	raw := []string{
+		"2.0.0", "1.0.0", "2.0.0-rc.10", "1.0.0-alpha.beta", "2.0.0-rc.2",
+		"1.0.0-alpha", "1.0.0-rc.1", "0.9.0", "1.0.0-alpha.1",
+	}
+	c := make(Collection, 0, len(raw))
+	for _, r := range raw {
+		v, _ := Parse(r)
+		c = append(c, v)
+	}
+
+	sort.Sort(c)
+
+	sorted := make([]string, 0, len(c))
+	for _, v := range c {
+		sorted = append(sorted, v.ToString())
+	}
+	assert.Equal(t, []string{
+		"0.9.0",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"2.0.0-rc.2",
+		"2.0.0-rc.10",
+		"2.0.0",
+	}, sorted)
+}
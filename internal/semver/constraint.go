@@ -0,0 +1,342 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NOTE(joel): Like semVerRegex, but minor and patch may be wildcards.
+const partialVersionRegex string = `v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?` +
+	`(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?`
+
+var hyphenRangeRegexp, operatorRegexp, comparatorRegexp *regexp.Regexp
+
+func init() {
+	hyphenRangeRegexp = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+	operatorRegexp = regexp.MustCompile(`(>=|<=|!=|==|~>|[<>=~^])\s+`)
+	comparatorRegexp = regexp.MustCompile(
+		`^(>=|<=|!=|==|~>|[<>=~^])?(` + partialVersionRegex + `)$`,
+	)
+}
+
+// Constraint is a parsed version range like `>=1.2.0 <2.0.0 || ^3.1`.
+//
+// Supported syntax:
+//   - comparison operators: `=`, `!=`, `>`, `<`, `>=`, `<=`
+//   - tilde ranges: `~1.2.3` (>=1.2.3 <1.3.0)
+//   - caret ranges: `^1.2.3` (>=1.2.3 <2.0.0), `^0.2.3` (>=0.2.3 <0.3.0)
+//   - wildcards: `1.x`, `1.2.*`, `*`
+//   - hyphen ranges: `1.2.3 - 2.3.4` (>=1.2.3 <=2.3.4)
+//   - AND: comparators separated by spaces or commas
+//   - OR: groups separated by `||`
+//
+// Pre-release versions are compared by precedence, so `2.0.0-rc.1` satisfies
+// `>=1.0.0` but not `1.x`.
+type Constraint struct {
+	original string
+	groups   [][]comparator
+}
+
+type comparator struct {
+	op string
+	v  *Version
+}
+
+// NOTE(joel): partial is a version where minor and patch may be missing or
+// wildcards, e.g. `1`, `1.2` or `1.x`.
+type partial struct {
+	major, minor, patch          uint64
+	hasMajor, hasMinor, hasPatch bool
+	pre                          string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ParseConstraint parses a version range. See Constraint for the supported
+// syntax.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{original: s}
+
+	for _, group := range strings.Split(s, "||") {
+		comparators, err := parseGroup(group)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint '%s': %s", s, err)
+		}
+		c.groups = append(c.groups, comparators)
+	}
+
+	return c, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Check reports whether the version satisfies the constraint.
+func (c *Constraint) Check(v *Version) bool {
+	for _, group := range c.groups {
+		ok := true
+		for _, cmp := range group {
+			if !cmp.check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Latest returns the version with the highest precedence that satisfies the
+// constraint or nil if none does.
+func (c *Constraint) Latest(versions []*Version) *Version {
+	var latest *Version
+	for _, v := range versions {
+		if c.Check(v) && (latest == nil || v.GreaterThan(latest)) {
+			latest = v
+		}
+	}
+	return latest
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// String returns the original constraint string.
+func (c *Constraint) String() string {
+	return c.original
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// parseGroup parses a group of AND-ed comparators or a hyphen range.
+func parseGroup(group string) ([]comparator, error) {
+	group = strings.TrimSpace(group)
+	if group == "" {
+		return nil, errors.New("empty range")
+	}
+
+	if m := hyphenRangeRegexp.FindStringSubmatch(group); m != nil {
+		from, err := parsePartial(m[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parsePartial(m[2])
+		if err != nil {
+			return nil, err
+		}
+		return append(lowerBound(">=", from), upperBound("<=", to)...), nil
+	}
+
+	// NOTE(joel): Allow whitespace between operator and version, e.g. `>= 1.2`.
+	group = operatorRegexp.ReplaceAllString(group, "$1")
+
+	comparators := make([]comparator, 0)
+	for _, token := range strings.FieldsFunc(group, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	}) {
+		parsed, err := parseComparator(token)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, parsed...)
+	}
+	return comparators, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// parseComparator desugars a single comparator like `^1.2` into one or more
+// primitive comparators.
+func parseComparator(token string) ([]comparator, error) {
+	m := comparatorRegexp.FindStringSubmatch(token)
+	if m == nil {
+		return nil, fmt.Errorf("invalid comparator '%s'", token)
+	}
+	op := m[1]
+	p, err := parsePartial(m[2])
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "", "=", "==":
+		if p.isFull() {
+			return []comparator{{"=", p.version()}}, nil
+		}
+		return append(lowerBound(">=", p), upperBound("<=", p)...), nil
+	case "!=":
+		if !p.isFull() {
+			return nil, fmt.Errorf("'%s' requires a full version", token)
+		}
+		return []comparator{{"!=", p.version()}}, nil
+	case ">", ">=":
+		return lowerBound(op, p), nil
+	case "<", "<=":
+		return upperBound(op, p), nil
+	case "~", "~>":
+		// NOTE(joel): Allow patch-level changes if a minor version is given,
+		// minor-level changes otherwise.
+		lower := lowerBound(">=", p)
+		if !p.hasMinor {
+			return append(lower, exclusiveUpper(p.major+1, 0, 0)), nil
+		}
+		return append(lower, exclusiveUpper(p.major, p.minor+1, 0)), nil
+	case "^":
+		// NOTE(joel): Allow changes that don't modify the left-most non-zero
+		// component.
+		lower := lowerBound(">=", p)
+		switch {
+		case p.major != 0 || !p.hasMinor:
+			return append(lower, exclusiveUpper(p.major+1, 0, 0)), nil
+		case p.minor != 0 || !p.hasPatch:
+			return append(lower, exclusiveUpper(0, p.minor+1, 0)), nil
+		default:
+			return append(lower, exclusiveUpper(0, 0, p.patch+1)), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown operator '%s'", op)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// lowerBound returns the comparators for `>p` or `>=p`, respecting missing
+// version components.
+func lowerBound(op string, p partial) []comparator {
+	if !p.hasMajor {
+		// NOTE(joel): `>*` matches nothing, `>=*` matches everything.
+		if op == ">" {
+			return []comparator{{"<", &Version{pre: "0"}}}
+		}
+		return nil
+	}
+	if p.isFull() {
+		return []comparator{{op, p.version()}}
+	}
+	if op == ">=" {
+		return []comparator{{">=", &Version{major: p.major, minor: p.minor}}}
+	}
+	// NOTE(joel): `>1.2` means greater than any 1.2.x version.
+	if !p.hasMinor {
+		return []comparator{{">=", &Version{major: p.major + 1}}}
+	}
+	return []comparator{{">=", &Version{major: p.major, minor: p.minor + 1}}}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// upperBound returns the comparators for `<p` or `<=p`, respecting missing
+// version components.
+func upperBound(op string, p partial) []comparator {
+	if !p.hasMajor {
+		// NOTE(joel): `<*` matches nothing, `<=*` matches everything.
+		if op == "<" {
+			return []comparator{{"<", &Version{pre: "0"}}}
+		}
+		return nil
+	}
+	if p.isFull() {
+		return []comparator{{op, p.version()}}
+	}
+	if op == "<" {
+		return []comparator{exclusiveUpper(p.major, p.minor, 0)}
+	}
+	// NOTE(joel): `<=1.2` means lower than or equal to any 1.2.x version.
+	if !p.hasMinor {
+		return []comparator{exclusiveUpper(p.major+1, 0, 0)}
+	}
+	return []comparator{exclusiveUpper(p.major, p.minor+1, 0)}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// exclusiveUpper returns a `<major.minor.patch-0` comparator. The `-0`
+// pre-release is the lowest possible version of major.minor.patch, so
+// pre-releases of the upper bound are excluded as well.
+func exclusiveUpper(major, minor, patch uint64) comparator {
+	return comparator{"<", &Version{major: major, minor: minor, patch: patch, pre: "0"}}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (cmp comparator) check(v *Version) bool {
+	d := v.Compare(cmp.v)
+	switch cmp.op {
+	case "=":
+		return d == 0
+	case "!=":
+		return d != 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// parsePartial parses a version where minor and patch may be missing or
+// wildcards.
+func parsePartial(s string) (partial, error) {
+	m := comparatorRegexp.FindStringSubmatch(s)
+	if m == nil || m[1] != "" {
+		return partial{}, fmt.Errorf("invalid version '%s'", s)
+	}
+
+	p := partial{pre: m[6]}
+	components := []struct {
+		raw   string
+		value *uint64
+		has   *bool
+	}{
+		{m[3], &p.major, &p.hasMajor},
+		{m[4], &p.minor, &p.hasMinor},
+		{m[5], &p.patch, &p.hasPatch},
+	}
+
+	wildcard := false
+	for _, c := range components {
+		if c.raw == "" || c.raw == "x" || c.raw == "X" || c.raw == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard {
+			return partial{}, fmt.Errorf("invalid version '%s'", s)
+		}
+		n, err := strconv.ParseUint(c.raw, 10, 64)
+		if err != nil {
+			return partial{}, fmt.Errorf("invalid version '%s'", s)
+		}
+		*c.value = n
+		*c.has = true
+	}
+
+	if p.pre != "" {
+		if !p.isFull() {
+			return partial{}, fmt.Errorf("invalid version '%s'", s)
+		}
+		if err := validatePrerelease(p.pre); err != nil {
+			return partial{}, fmt.Errorf("invalid version '%s'", s)
+		}
+	}
+
+	return p, nil
+}
+
+func (p partial) isFull() bool {
+	return p.hasMajor && p.hasMinor && p.hasPatch
+}
+
+func (p partial) version() *Version {
+	return &Version{major: p.major, minor: p.minor, patch: p.patch, pre: p.pre}
+}
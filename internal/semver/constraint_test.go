@@ -0,0 +1,129 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		// NOTE(joel): Comparison operators
+		{"1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+		{"==v1.2.3", "1.2.3+build", true},
+		{"!=1.2.3", "1.2.3", false},
+		{"!=1.2.3", "1.2.4", true},
+		{">1.2.3", "1.2.4", true},
+		{">1.2.3", "1.2.3", false},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{">1", "1.9.9", false},
+		{">=1.2.3", "1.2.3", true},
+		{">= 1.2", "1.2.0", true},
+		{"<1.2.3", "1.2.2", true},
+		{"<1.2", "1.2.0", false},
+		{"<1.2", "1.2.0-rc.1", false},
+		{"<=1.2.3", "1.2.3", true},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		// NOTE(joel): Tilde ranges
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1.2.3", "1.2.2", false},
+		{"~1.2", "1.2.0", true},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+		{"~>1.4", "1.4.7", true},
+		// NOTE(joel): Caret ranges
+		{"^1.2.3", "1.9.9", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "2.0.0-rc.1", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.0", "0.0.9", true},
+		{"^0.0", "0.1.0", false},
+		{"^1.2.3-beta.2", "1.2.3-beta.4", true},
+		{"^1.2.3-beta.2", "1.2.3-beta.1", false},
+		// NOTE(joel): Wildcards
+		{"*", "3.4.5", true},
+		{"1.x", "1.4.0", true},
+		{"1.x", "1.1.0-next.1", true},
+		{"1.x", "2.0.0", false},
+		{"1.x", "2.0.0-rc.1", false},
+		{"1.2.*", "1.2.7", true},
+		{"1.2.X", "1.3.0", false},
+		{"1", "1.5.0", true},
+		// NOTE(joel): Hyphen ranges
+		{"1.2.3 - 2.3.4", "1.2.3", true},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2.3 - 2.3.4", "2.3.5", false},
+		{"1.2 - 2.3", "2.3.9", true},
+		{"1.2 - 2", "2.9.9", true},
+		{"1.2 - 2", "3.0.0", false},
+		// NOTE(joel): AND / OR
+		{">=2.0.0 <3.0.0", "2.5.0", true},
+		{">=2.0.0 <3.0.0", "3.0.0", false},
+		{">=2.0.0, <3.0.0", "1.0.0", false},
+		{"^1.0.0 || ^3.0.0", "3.1.0", true},
+		{"^1.0.0 || ^3.0.0", "2.1.0", false},
+		{"<1.0.0 || >=2.0.0 <2.1.0", "2.0.5", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint+" @ "+test.version, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := ParseConstraint(test.constraint)
+			require.NoError(t, err)
+			v, err := Parse(test.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, c.Check(v))
+		})
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	for _, constraint := range []string{
+		"",
+		"1.2.3 ||",
+		"foo",
+		">>1.2.3",
+		"1.x.3",
+		"!=1.2",
+		"1.2-rc.1",
+		"1.2.3 - ",
+	} {
+		t.Run(constraint, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseConstraint(constraint)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConstraintLatest(t *testing.T) {
+	versions := make([]*Version, 0)
+	for _, s := range []string{"1.3.0", "1.4.0", "1.4.2", "1.5.0", "2.0.0"} {
+		v, _ := Parse(s)
+		versions = append(versions, v)
+	}
+
+	c, err := ParseConstraint("~1.4")
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.2", c.Latest(versions).ToString())
+	assert.Equal(t, "~1.4", c.String())
+
+	c, err = ParseConstraint(">=3")
+	require.NoError(t, err)
+	assert.Nil(t, c.Latest(versions))
+}
@@ -21,6 +21,13 @@ const (
 	ReleaseTypeMajor
 )
 
+var releaseTypeNames = map[string]int{
+	"none":  ReleaseTypeNone,
+	"patch": ReleaseTypePatch,
+	"minor": ReleaseTypeMinor,
+	"major": ReleaseTypeMajor,
+}
+
 // NOTE(joel): This is not the official regex from the semver spec. It has been
 // modified to allow for loose handling where versions like 2.1 are detected.
 const semVerRegex string = `v?(0|[1-9]\d*)(?:\.(0|[1-9]\d*))?(?:\.(0|[1-9]\d*))?` +
@@ -117,6 +124,57 @@ func (v *Version) ToString() string {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+// Major returns the major version.
+func (v *Version) Major() uint64 {
+	return v.major
+}
+
+// Minor returns the minor version.
+func (v *Version) Minor() uint64 {
+	return v.minor
+}
+
+// Patch returns the patch version.
+func (v *Version) Patch() uint64 {
+	return v.patch
+}
+
+// Prerelease returns the pre-release identifiers, e.g. `rc.1`.
+func (v *Version) Prerelease() string {
+	return v.pre
+}
+
+// Metadata returns the build metadata.
+func (v *Version) Metadata() string {
+	return v.metadata
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// PrereleaseCounter returns the numeric counter N if the pre-release of the
+// version has the form `<id>.N`.
+func (v *Version) PrereleaseCounter(id string) (uint64, bool) {
+	counter, ok := strings.CutPrefix(v.pre, id+".")
+	if !ok || counter == "" || !containsOnly(counter, num) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(counter, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// SameCore reports whether both versions have the same major, minor and patch
+// version.
+func (v *Version) SameCore(o *Version) bool {
+	return v.major == o.major && v.minor == o.minor && v.patch == o.patch
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 // IncPatch produces the next patch version
 func (v *Version) IncPatch() {
 	// NOTE(joel): According to http://semver.org/#spec-item-9, pre-release
@@ -233,3 +291,56 @@ func Bump(v Version, release int) (*Version, error) {
 
 	return &v, nil
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ParseReleaseType parses a release type name (none, patch, minor, major).
+func ParseReleaseType(s string) (int, error) {
+	if t, ok := releaseTypeNames[strings.ToLower(s)]; ok {
+		return t, nil
+	}
+	return ReleaseTypeNone, fmt.Errorf(
+		"invalid release type '%s', must be one of none, patch, minor, major", s,
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ReleaseTypeName returns the name of the given release type.
+func ReleaseTypeName(release int) string {
+	for name, t := range releaseTypeNames {
+		if t == release {
+			return name
+		}
+	}
+	return "none"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NextPrerelease returns the pre-release `<base>-<id>.N` of the given base
+// version. N is one higher than the highest counter of the existing versions
+// with the same base and identifier, starting at 1.
+func NextPrerelease(base Version, id string, existing []*Version) (*Version, error) {
+	if id == "" {
+		return nil, errors.New("pre-release identifier must not be empty")
+	}
+	if err := validatePrerelease(id); err != nil {
+		return nil, fmt.Errorf("invalid pre-release identifier '%s': %s", id, err)
+	}
+
+	var counter uint64
+	for _, e := range existing {
+		if !e.SameCore(&base) {
+			continue
+		}
+		if n, ok := e.PrereleaseCounter(id); ok && n > counter {
+			counter = n
+		}
+	}
+
+	base.metadata = ""
+	base.pre = fmt.Sprintf("%s.%d", id, counter+1)
+	base.Original = fmt.Sprintf("%s%s", originalVPrefix(&base), base.ToString())
+	return &base, nil
+}
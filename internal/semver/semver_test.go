@@ -61,3 +61,67 @@ func TestBump(t *testing.T) {
 	v3, _ := Bump(*v, ReleaseTypeMajor)
 	assert.Equal(t, "2.0.0", v3.ToString())
 }
+
+func TestParseReleaseType(t *testing.T) {
+	for name, expected := range map[string]int{
+		"none":  ReleaseTypeNone,
+		"patch": ReleaseTypePatch,
+		"Minor": ReleaseTypeMinor,
+		"MAJOR": ReleaseTypeMajor,
+	} {
+		got, err := ParseReleaseType(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, got)
+	}
+
+	_, err := ParseReleaseType("tiny")
+	assert.Error(t, err)
+	assert.Equal(t, "minor", ReleaseTypeName(ReleaseTypeMinor))
+}
+
+func TestPrereleaseCounter(t *testing.T) {
+	v, _ := Parse("2.0.0-rc.12")
+	n, ok := v.PrereleaseCounter("rc")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(12), n)
+
+	_, ok = v.PrereleaseCounter("beta")
+	assert.False(t, ok)
+
+	v, _ = Parse("2.0.0-rc.1.fix")
+	_, ok = v.PrereleaseCounter("rc")
+	assert.False(t, ok)
+}
+
+func TestNextPrerelease(t *testing.T) {
+	parse := func(versions ...string) []*Version {
+		parsed := make([]*Version, 0)
+		for _, s := range versions {
+			v, _ := Parse(s)
+			parsed = append(parsed, v)
+		}
+		return parsed
+	}
+
+	base, _ := Parse("v2.0.0")
+
+	v, err := NextPrerelease(*base, "rc", parse("v1.0.0"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0-rc.1", v.ToString())
+	assert.Equal(t, "v2.0.0-rc.1", v.Original)
+	assert.Equal(t, "2.0.0", base.ToString())
+
+	v, err = NextPrerelease(*base, "rc", parse("v2.0.0-rc.1", "v2.0.0-rc.10", "v2.0.0-rc.2", "v1.0.0-rc.11", "v2.0.0-beta.20"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0-rc.11", v.ToString())
+
+	v, err = NextPrerelease(*base, "beta", parse("v2.0.0-rc.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0-beta.1", v.ToString())
+
+	_, err = NextPrerelease(*base, "", nil)
+	assert.Error(t, err)
+
+	_, err = NextPrerelease(*base, "r_c", nil)
+	assert.Error(t, err)
+}